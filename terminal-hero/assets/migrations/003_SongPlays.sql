@@ -0,0 +1,2 @@
+ALTER TABLE Songs ADD COLUMN PlayCount INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE Songs ADD COLUMN LastPlayed INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,15 @@
+CREATE TABLE Playlists (
+    Id INTEGER PRIMARY KEY AUTOINCREMENT,
+    Name VARCHAR(255) NOT NULL UNIQUE
+);
+
+CREATE TABLE PlaylistSongs (
+    Id INTEGER PRIMARY KEY AUTOINCREMENT,
+    PlaylistId INTEGER NOT NULL,
+    ChartHash VARCHAR(255) NOT NULL,
+    Name VARCHAR(255) NOT NULL,
+    RelativePath VARCHAR(255) NOT NULL,
+    Position INTEGER NOT NULL,
+    FOREIGN KEY(PlaylistId) REFERENCES Playlists(Id)
+    UNIQUE(PlaylistId, ChartHash)
+);
@@ -0,0 +1,8 @@
+CREATE TABLE CareerSongs (
+    Id INTEGER PRIMARY KEY AUTOINCREMENT,
+    Profile VARCHAR(255) NOT NULL,
+    Career VARCHAR(255) NOT NULL,
+    ChartHash VARCHAR(255) NOT NULL,
+    Stars INTEGER NOT NULL,
+    UNIQUE(Profile, Career, ChartHash)
+);
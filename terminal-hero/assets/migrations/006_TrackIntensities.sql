@@ -0,0 +1,7 @@
+CREATE TABLE TrackIntensities (
+    Id INTEGER PRIMARY KEY AUTOINCREMENT,
+    ChartHash VARCHAR(255) NOT NULL,
+    TrackName VARCHAR(255) NOT NULL,
+    Intensity REAL NOT NULL,
+    UNIQUE(ChartHash, TrackName)
+);
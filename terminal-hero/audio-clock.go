@@ -0,0 +1,106 @@
+package main
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/faiface/beep"
+)
+
+const (
+	// if the display clock is further than this from the audio, it jumps to the audio instead of catching up
+	audioClockSnapMs = 200.0
+	// how much of the difference between the display clock and the audio is made up each update
+	audioClockSmoothing = 0.2
+)
+
+// counts the samples that the speaker has taken from the stream, which is where playback is
+type positionStreamer struct {
+	streamer beep.Streamer
+	samples  atomic.Int64
+}
+
+func (p *positionStreamer) Stream(samples [][2]float64) (n int, ok bool) {
+	n, ok = p.streamer.Stream(samples)
+	p.samples.Add(int64(n))
+	return n, ok
+}
+
+func (p *positionStreamer) Err() error {
+	return p.streamer.Err()
+}
+
+// the song time in milliseconds, based on how much of the song the speaker has played.
+// the speaker takes samples a buffer at a time, so the time between buffers is
+// filled in with the wall clock, and changes are smoothed so the highway doesn't jump
+type audioClock struct {
+	position *positionStreamer
+	format   beep.Format
+
+	lastSamples int64
+	lastChange  time.Time // when the speaker last took samples
+	smoothedMs  float64
+	lastUpdate  time.Time
+}
+
+func newAudioClock(position *positionStreamer, format beep.Format) audioClock {
+	return audioClock{position: position, format: format}
+}
+
+// the song time that the samples the speaker has taken add up to, minus what's still in the speaker's buffer
+func (c audioClock) audioMs(now time.Time) float64 {
+	bufferMs := float64(speakerBufferDuration / time.Millisecond)
+	sinceChangeMs := float64(now.Sub(c.lastChange)) / float64(time.Millisecond)
+	if sinceChangeMs > bufferMs {
+		// the audio stalled or is paused
+		sinceChangeMs = bufferMs
+	}
+	if sinceChangeMs < 0 {
+		sinceChangeMs = 0
+	}
+
+	streamedMs := float64(c.format.SampleRate.D(int(c.lastSamples))) / float64(time.Millisecond)
+	return streamedMs - bufferMs + sinceChangeMs
+}
+
+// starts the clock at the time that the music starts. the speaker hasn't taken the first buffer
+// yet, so the last change is a buffer earlier to put the audio at the start of the song, and the
+// clock starts at the audio position instead of catching up to it
+func (c audioClock) start(now time.Time) audioClock {
+	c.lastSamples = c.position.samples.Load()
+	c.lastChange = now.Add(-speakerBufferDuration)
+	c.smoothedMs = c.audioMs(now)
+	c.lastUpdate = now
+	return c
+}
+
+// after a pause, the time spent paused shouldn't count
+func (c audioClock) resume(now time.Time) audioClock {
+	c.lastChange = now
+	c.lastUpdate = now
+	return c
+}
+
+// moves the clock forward to now and nudges it towards the audio position
+func (c audioClock) update(now time.Time) audioClock {
+	samples := c.position.samples.Load()
+	if samples != c.lastSamples {
+		c.lastSamples = samples
+		c.lastChange = now
+	}
+
+	c.smoothedMs = c.at(now)
+	difference := c.audioMs(now) - c.smoothedMs
+	if difference > audioClockSnapMs || difference < -audioClockSnapMs {
+		c.smoothedMs += difference
+	} else {
+		c.smoothedMs += difference * audioClockSmoothing
+	}
+	c.lastUpdate = now
+	return c
+}
+
+// the smoothed song time at now, without updating from the audio
+func (c audioClock) at(now time.Time) float64 {
+	return c.smoothedMs + float64(now.Sub(c.lastUpdate))/float64(time.Millisecond)
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/faiface/beep"
+)
+
+func streamSilence(p *positionStreamer, duration time.Duration, format beep.Format) {
+	p.Stream(make([][2]float64, format.SampleRate.N(duration)))
+}
+
+func newTestAudioClock() (audioClock, *positionStreamer, beep.Format) {
+	format := beep.Format{SampleRate: 44100, NumChannels: 2, Precision: 2}
+	position := &positionStreamer{streamer: beep.Silence(-1)}
+	return newAudioClock(position, format), position, format
+}
+
+func TestAudioClock_FollowsTheSpeaker(t *testing.T) {
+	clock, position, format := newTestAudioClock()
+	start := time.Now()
+	clock = clock.start(start)
+
+	// the speaker takes a buffer at a time, which is played while the next one is taken
+	streamSilence(position, speakerBufferDuration, format)
+	clock = clock.update(start)
+	if ms := clock.at(start); ms != 0 {
+		t.Error("Expected 0ms at the start, got", ms)
+	}
+
+	clock = clock.update(start.Add(50 * time.Millisecond))
+	if ms := clock.at(start.Add(50 * time.Millisecond)); ms != 50 {
+		t.Error("Expected 50ms between buffers, got", ms)
+	}
+
+	streamSilence(position, speakerBufferDuration, format)
+	clock = clock.update(start.Add(100 * time.Millisecond))
+	if ms := clock.at(start.Add(100 * time.Millisecond)); ms != 100 {
+		t.Error("Expected 100ms after the next buffer, got", ms)
+	}
+}
+
+func TestAudioClock_StopsWhenTheAudioStalls(t *testing.T) {
+	clock, position, format := newTestAudioClock()
+	start := time.Now()
+	clock = clock.start(start)
+	streamSilence(position, speakerBufferDuration, format)
+	clock = clock.update(start)
+
+	// the speaker didn't take anything for a long time, so only the first buffer was heard
+	stalled := start.Add(time.Second)
+	clock = clock.update(stalled)
+	if ms := clock.at(stalled); ms != 100 {
+		t.Error("Expected the clock to jump back to 100ms, got", ms)
+	}
+}
+
+func TestAudioClock_SmoothsSmallDifferences(t *testing.T) {
+	clock, position, format := newTestAudioClock()
+	start := time.Now()
+	clock = clock.start(start)
+
+	// the audio is 50ms ahead
+	streamSilence(position, speakerBufferDuration+50*time.Millisecond, format)
+	clock = clock.update(start)
+
+	ms := clock.at(start)
+	if ms <= 0 || ms >= 50 {
+		t.Error("Expected the clock to move part of the way to 50ms, got", ms)
+	}
+}
+
+func TestAudioClock_Resume(t *testing.T) {
+	clock, position, format := newTestAudioClock()
+	start := time.Now()
+	clock = clock.start(start)
+	streamSilence(position, speakerBufferDuration, format)
+	clock = clock.update(start)
+	clock = clock.update(start.Add(20 * time.Millisecond))
+
+	// paused for 10 seconds
+	resumed := start.Add(10 * time.Second)
+	clock = clock.resume(resumed)
+	if ms := clock.at(resumed); ms != 20 {
+		t.Error("Expected the time spent paused to not count, got", ms)
+	}
+}
+
+func TestFloorDiv(t *testing.T) {
+	testCases := []struct{ a, b, expected int }{
+		{7, 2, 3},
+		{-7, 2, -4},
+		{-6, 2, -3},
+		{0, 30, 0},
+		{-1, 30, -1},
+	}
+	for _, tc := range testCases {
+		if actual := floorDiv(tc.a, tc.b); actual != tc.expected {
+			t.Errorf("Expected %d / %d to be %d, got %d", tc.a, tc.b, tc.expected, actual)
+		}
+	}
+}
+
+func TestAudioClock_StartsLockedToTheAudio(t *testing.T) {
+	clock, position, format := newTestAudioClock()
+	start := time.Now()
+	clock = clock.start(start)
+	if ms := clock.at(start); ms != clock.audioMs(start) || ms != 0 {
+		t.Error("Expected the clock to start at the audio position, 0ms, got", ms)
+	}
+
+	// the speaker takes the first buffer a little after the start
+	taken := start.Add(10 * time.Millisecond)
+	streamSilence(position, speakerBufferDuration, format)
+	clock = clock.update(taken)
+	if ms := clock.at(taken); ms < 0 || ms > 10 {
+		t.Error("Expected the clock to stay near the start, got", ms)
+	}
+}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/speaker"
+	"github.com/pkg/errors"
+)
+
+// where the speaker's sound goes, chosen with the -audio flag
+const (
+	audioOutputAuto   = "auto"   // the audio device, or nothing if there isn't one
+	audioOutputDevice = "device" // the audio device
+	audioOutputNull   = "null"   // nothing, for running without an audio device
+	audioOutputWav    = "wav"    // a wav file
+)
+
+var audioOutputs = []string{audioOutputAuto, audioOutputDevice, audioOutputNull, audioOutputWav}
+
+const (
+	wavOutputBitsPerSample = 16
+	wavOutputHeaderSize    = 44
+)
+
+type audioOutput interface {
+	play(stream beep.Streamer)
+	clear()
+	close() error
+}
+
+func isValidAudioOutput(kind string) bool {
+	for _, output := range audioOutputs {
+		if output == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// opens the output. when there's no audio device, auto falls back to the null output
+func openAudioOutput(kind string, wavPath string, sampleRate beep.SampleRate, bufferSize int) (audioOutput, error) {
+	switch kind {
+	case audioOutputNull:
+		return startSinkOutput(nullSink{}, sampleRate, bufferSize), nil
+	case audioOutputWav:
+		sink, err := createWavSink(wavPath, sampleRate)
+		if err != nil {
+			return nil, err
+		}
+		return startSinkOutput(sink, sampleRate, bufferSize), nil
+	case audioOutputDevice:
+		return openDeviceOutput(sampleRate, bufferSize)
+	case audioOutputAuto, "":
+		output, err := openDeviceOutput(sampleRate, bufferSize)
+		if err != nil {
+			log.Info("no audio device found, playing without sound", "err", err)
+			return startSinkOutput(nullSink{}, sampleRate, bufferSize), nil
+		}
+		return output, nil
+	}
+	return nil, errors.Errorf("unknown audio output %q", kind)
+}
+
+// plays through the audio device with beep's speaker
+type deviceOutput struct{}
+
+func openDeviceOutput(sampleRate beep.SampleRate, bufferSize int) (deviceOutput, error) {
+	err := speaker.Init(sampleRate, bufferSize)
+	return deviceOutput{}, err
+}
+
+func (deviceOutput) play(stream beep.Streamer) {
+	speaker.Play(stream)
+}
+
+func (deviceOutput) clear() {
+	speaker.Clear()
+}
+
+func (deviceOutput) close() error {
+	speaker.Close()
+	return nil
+}
+
+// takes the mixed samples instead of an audio device
+type audioSink interface {
+	write(samples [][2]float64) error
+	close() error
+}
+
+// mixes the streams in real time like the speaker does, and gives the samples to a sink.
+// the mixer is guarded by the speaker's lock, so speaker.Lock still stops the streams
+// being pulled while they're changed
+type sinkOutput struct {
+	sink       audioSink
+	sampleRate beep.SampleRate
+	mixer      beep.Mixer
+	samples    [][2]float64
+
+	done     chan struct{}
+	finished sync.WaitGroup
+	err      error
+}
+
+func newSinkOutput(sink audioSink, sampleRate beep.SampleRate, bufferSize int) *sinkOutput {
+	return &sinkOutput{
+		sink:       sink,
+		sampleRate: sampleRate,
+		samples:    make([][2]float64, bufferSize),
+		done:       make(chan struct{}),
+	}
+}
+
+func startSinkOutput(sink audioSink, sampleRate beep.SampleRate, bufferSize int) *sinkOutput {
+	o := newSinkOutput(sink, sampleRate, bufferSize)
+	o.finished.Add(1)
+	go o.run()
+	return o
+}
+
+// pulls a buffer at a time, as fast as the samples would be played
+func (o *sinkOutput) run() {
+	defer o.finished.Done()
+
+	start := time.Now()
+	rendered := 0
+	ticker := time.NewTicker(o.sampleRate.D(len(o.samples)))
+	defer ticker.Stop()
+	for {
+		due := o.sampleRate.N(time.Since(start))
+		for rendered+len(o.samples) <= due {
+			err := o.render(len(o.samples))
+			if err != nil {
+				log.Error("audio output failed", "err", err)
+				o.err = err
+				return
+			}
+			rendered += len(o.samples)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-o.done:
+			return
+		}
+	}
+}
+
+// mixes n samples and gives them to the sink
+func (o *sinkOutput) render(n int) error {
+	samples := o.samples[:n]
+	speaker.Lock()
+	o.mixer.Stream(samples)
+	speaker.Unlock()
+	return o.sink.write(samples)
+}
+
+func (o *sinkOutput) play(stream beep.Streamer) {
+	speaker.Lock()
+	o.mixer.Add(stream)
+	speaker.Unlock()
+}
+
+func (o *sinkOutput) clear() {
+	speaker.Lock()
+	o.mixer.Clear()
+	speaker.Unlock()
+}
+
+func (o *sinkOutput) close() error {
+	select {
+	case <-o.done:
+		return nil
+	default:
+		close(o.done)
+	}
+	o.finished.Wait()
+
+	err := o.sink.close()
+	if o.err != nil {
+		return o.err
+	}
+	return err
+}
+
+// throws the samples away
+type nullSink struct{}
+
+func (nullSink) write(samples [][2]float64) error {
+	return nil
+}
+
+func (nullSink) close() error {
+	return nil
+}
+
+// writes 16 bit stereo pcm. the sizes in the header are filled in on close
+type wavSink struct {
+	file       io.WriteSeeker
+	closer     io.Closer
+	sampleRate beep.SampleRate
+	dataSize   int
+	buf        []byte
+}
+
+func createWavSink(filePath string, sampleRate beep.SampleRate) (*wavSink, error) {
+	if filePath == "" {
+		return nil, errors.New("no file given for the wav output")
+	}
+	file, err := os.Create(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	sink := &wavSink{file: file, closer: file, sampleRate: sampleRate}
+	err = sink.writeHeader()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return sink, nil
+}
+
+func (w *wavSink) writeHeader() error {
+	channels := 2
+	bytesPerFrame := channels * wavOutputBitsPerSample / 8
+
+	header := make([]byte, 0, wavOutputHeaderSize)
+	header = append(header, "RIFF"...)
+	header = binary.LittleEndian.AppendUint32(header, uint32(wavOutputHeaderSize-8+w.dataSize))
+	header = append(header, "WAVEfmt "...)
+	header = binary.LittleEndian.AppendUint32(header, 16)
+	header = binary.LittleEndian.AppendUint16(header, 1) // pcm
+	header = binary.LittleEndian.AppendUint16(header, uint16(channels))
+	header = binary.LittleEndian.AppendUint32(header, uint32(w.sampleRate))
+	header = binary.LittleEndian.AppendUint32(header, uint32(int(w.sampleRate)*bytesPerFrame))
+	header = binary.LittleEndian.AppendUint16(header, uint16(bytesPerFrame))
+	header = binary.LittleEndian.AppendUint16(header, wavOutputBitsPerSample)
+	header = append(header, "data"...)
+	header = binary.LittleEndian.AppendUint32(header, uint32(w.dataSize))
+
+	_, err := w.file.Seek(0, io.SeekStart)
+	if err != nil {
+		return err
+	}
+	_, err = w.file.Write(header)
+	return err
+}
+
+func (w *wavSink) write(samples [][2]float64) error {
+	w.buf = w.buf[:0]
+	for _, sample := range samples {
+		for _, value := range sample {
+			// clipped like the speaker does
+			if value < -1 {
+				value = -1
+			}
+			if value > 1 {
+				value = 1
+			}
+			w.buf = binary.LittleEndian.AppendUint16(w.buf, uint16(int16(value*(1<<15-1))))
+		}
+	}
+	_, err := w.file.Write(w.buf)
+	if err != nil {
+		return errors.Wrap(err, "wav output")
+	}
+	w.dataSize += len(w.buf)
+	return nil
+}
+
+func (w *wavSink) close() error {
+	err := w.writeHeader()
+	closeErr := w.closer.Close()
+	if err != nil {
+		return errors.Wrap(err, "wav output")
+	}
+	if closeErr != nil {
+		return errors.Wrap(closeErr, "wav output")
+	}
+	log.Info(fmt.Sprintf("wrote %d bytes of audio to the wav output", w.dataSize))
+	return nil
+}
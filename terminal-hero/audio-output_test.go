@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/faiface/beep"
+)
+
+// a constant value on the left and the negative on the right
+func constantStreamer(value float64) beep.Streamer {
+	return beep.StreamerFunc(func(samples [][2]float64) (n int, ok bool) {
+		for i := range samples {
+			samples[i] = [2]float64{value, -value}
+		}
+		return len(samples), true
+	})
+}
+
+func TestNullOutput_ConsumesStreamsInRealTime(t *testing.T) {
+	sampleRate := beep.SampleRate(44100)
+	output, err := openAudioOutput(audioOutputNull, "", sampleRate, sampleRate.N(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	position := &positionStreamer{streamer: beep.Silence(-1)}
+	start := time.Now()
+	output.play(position)
+	time.Sleep(200 * time.Millisecond)
+	err = output.close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	elapsed := time.Since(start)
+
+	// there can't be more samples than there was time to play them
+	played := sampleRate.D(int(position.samples.Load()))
+	if played < 100*time.Millisecond || played > elapsed+10*time.Millisecond {
+		t.Errorf("Expected about %s of audio to be taken, got %s", elapsed, played)
+	}
+}
+
+func TestWavOutput_WritesTheMix(t *testing.T) {
+	sampleRate := beep.SampleRate(22050)
+	filePath := filepath.Join(t.TempDir(), "out.wav")
+	sink, err := createWavSink(filePath, sampleRate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// rendered by hand instead of in real time
+	output := newSinkOutput(sink, sampleRate, 100)
+	output.play(constantStreamer(0.25))
+	output.play(constantStreamer(0.25))
+	for i := 0; i < 3; i++ {
+		err = output.render(100)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	output.clear()
+	err = output.render(50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = sink.close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != wavOutputHeaderSize+350*4 {
+		t.Fatal("Expected 350 stereo 16 bit samples after the header, got", len(data), "bytes")
+	}
+	if binary.LittleEndian.Uint32(data[4:8]) != uint32(len(data)-8) || binary.LittleEndian.Uint32(data[40:44]) != 350*4 {
+		t.Error("Expected the sizes in the header to be filled in")
+	}
+	if binary.LittleEndian.Uint32(data[24:28]) != uint32(sampleRate) {
+		t.Error("Expected the sample rate to be 22050, got", binary.LittleEndian.Uint32(data[24:28]))
+	}
+
+	sample := func(i int, channel int) int16 {
+		offset := wavOutputHeaderSize + i*4 + channel*2
+		return int16(binary.LittleEndian.Uint16(data[offset : offset+2]))
+	}
+	if sample(0, 0) != 16383 || sample(0, 1) != -16383 {
+		t.Error("Expected the two streams to be mixed, got", sample(0, 0), sample(0, 1))
+	}
+	if sample(349, 0) != 0 || sample(349, 1) != 0 {
+		t.Error("Expected silence after clearing, got", sample(349, 0), sample(349, 1))
+	}
+}
+
+func TestOpenAudioOutput_UnknownOutput(t *testing.T) {
+	_, err := openAudioOutput("speakers", "", 44100, 4410)
+	if err == nil {
+		t.Error("Expected an unknown output to fail")
+	}
+	if isValidAudioOutput("speakers") || !isValidAudioOutput(audioOutputWav) {
+		t.Error("Expected only the audio outputs to be valid")
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import "sort"
+
+type beatLineKind int
+
+const (
+	noBeatLine beatLineKind = iota
+	beatLine
+	measureLine
+)
+
+type beatLineTick struct {
+	tick int
+	kind beatLineKind
+}
+
+type beatLineTime struct {
+	timeMs int
+	kind   beatLineKind
+}
+
+// the length of a beat in ticks for a time signature denominator, which is stored as a power of 2
+func beatTicksForDenominator(resolution int, denominator int) int {
+	if denominator < 0 || denominator > 8 {
+		denominator = 2
+	}
+	beatTicks := resolution * 4 / (1 << denominator)
+	if beatTicks <= 0 {
+		return 1
+	}
+	return beatTicks
+}
+
+// the tick of every beat up to and including endTick. a time signature
+// change always starts a new measure, even if the previous one wasn't finished
+func getBeatLineTicks(chart *Chart, endTick int) []beatLineTick {
+	resolution := chart.SongMetadata.Resolution
+	if resolution <= 0 {
+		return nil
+	}
+
+	timeSignatures := make([]SyncTrackElement, 0)
+	for _, sync := range chart.SyncTrack {
+		if sync.Type == "TS" && sync.Value > 0 {
+			timeSignatures = append(timeSignatures, sync)
+		}
+	}
+
+	result := make([]beatLineTick, 0)
+	numerator := 4
+	beatTicks := resolution
+	beatInMeasure := 0
+	for tick := 0; tick <= endTick; {
+		for len(timeSignatures) > 0 && timeSignatures[0].TimeStamp <= tick {
+			ts := timeSignatures[0]
+			numerator = ts.Value
+			denominator, ok := chart.TimeSignatureDenominators[ts.TimeStamp]
+			if !ok {
+				denominator = 2
+			}
+			beatTicks = beatTicksForDenominator(resolution, denominator)
+			beatInMeasure = 0
+			timeSignatures = timeSignatures[1:]
+		}
+
+		kind := beatLine
+		if beatInMeasure == 0 {
+			kind = measureLine
+		}
+		result = append(result, beatLineTick{tick, kind})
+		beatInMeasure = (beatInMeasure + 1) % numerator
+
+		nextTick := tick + beatTicks
+		if len(timeSignatures) > 0 && timeSignatures[0].TimeStamp < nextTick {
+			nextTick = timeSignatures[0].TimeStamp
+		}
+		tick = nextTick
+	}
+	return result
+}
+
+// the real time of every beat up to endTick
+func getBeatLineTimes(chart *Chart, endTick int) []beatLineTime {
+	tempoMap := NewTempoMap(chart)
+	ticks := getBeatLineTicks(chart, endTick)
+	result := make([]beatLineTime, len(ticks))
+	for i, bt := range ticks {
+		result[i] = beatLineTime{int(tempoMap.TickToMs(bt.tick)), bt.kind}
+	}
+	return result
+}
+
+// the strongest beat line in [startMs, endMs). beatLines must be sorted by time
+func beatLineBetween(beatLines []beatLineTime, startMs int, endMs int) beatLineKind {
+	i := sort.Search(len(beatLines), func(i int) bool {
+		return beatLines[i].timeMs >= startMs
+	})
+
+	result := noBeatLine
+	for ; i < len(beatLines) && beatLines[i].timeMs < endMs; i++ {
+		if beatLines[i].kind > result {
+			result = beatLines[i].kind
+		}
+	}
+	return result
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGetBeatLineTicks_WithTimeSignatureChanges(t *testing.T) {
+	chart := &Chart{
+		SongMetadata:              SongMetadata{Resolution: 192},
+		SyncTrack:                 []SyncTrackElement{{0, "B", 120000}, {0, "TS", 3}, {576, "TS", 6}},
+		TimeSignatureDenominators: map[int]int{576: 3},
+	}
+
+	ticks := getBeatLineTicks(chart, 1248)
+
+	// 3/4 for one measure, then 6/8 which has beats half as long
+	expected := []beatLineTick{
+		{0, measureLine}, {192, beatLine}, {384, beatLine},
+		{576, measureLine}, {672, beatLine}, {768, beatLine}, {864, beatLine}, {960, beatLine}, {1056, beatLine},
+		{1152, measureLine}, {1248, beatLine},
+	}
+	if !reflect.DeepEqual(expected, ticks) {
+		t.Errorf("Expected %v, got %v", expected, ticks)
+	}
+}
+
+func TestGetBeatLineTicks_MidMeasureTimeSignatureStartsNewMeasure(t *testing.T) {
+	chart := &Chart{
+		SongMetadata: SongMetadata{Resolution: 100},
+		SyncTrack:    []SyncTrackElement{{0, "TS", 4}, {250, "TS", 2}},
+	}
+
+	ticks := getBeatLineTicks(chart, 450)
+
+	expected := []beatLineTick{{0, measureLine}, {100, beatLine}, {200, beatLine}, {250, measureLine}, {350, beatLine}, {450, measureLine}}
+	if !reflect.DeepEqual(expected, ticks) {
+		t.Errorf("Expected %v, got %v", expected, ticks)
+	}
+}
+
+func TestBeatLineBetween(t *testing.T) {
+	beatLines := []beatLineTime{{0, measureLine}, {500, beatLine}, {1000, beatLine}, {1500, measureLine}}
+
+	testCases := []struct {
+		start, end int
+		expected   beatLineKind
+	}{
+		{0, 30, measureLine},
+		{470, 500, noBeatLine},
+		{500, 530, beatLine},
+		{900, 1600, measureLine},
+		{1600, 1700, noBeatLine},
+	}
+
+	for _, tc := range testCases {
+		result := beatLineBetween(beatLines, tc.start, tc.end)
+		if result != tc.expected {
+			t.Errorf("Expected %d between %d and %d, got %d", tc.expected, tc.start, tc.end, result)
+		}
+	}
+}
+
+func TestViewHasBeatLinesBeforeNotes(t *testing.T) {
+	chart := openCultOfPersonalityChart(t)
+
+	model := createModelFromChart(chart, parseTrackName("ExpertSingle"), defaultSettings())
+	model.currentTimeMs = 5000
+	model.viewModel = model.CreateCurrentNoteChart()
+
+	measures := 0
+	beats := 0
+	for _, noteLine := range model.viewModel.NoteLine {
+		switch noteLine.BeatLine {
+		case measureLine:
+			measures++
+		case beatLine:
+			beats++
+		}
+	}
+
+	// the highway shows about a second of the song, and a beat is about 600ms
+	if measures+beats == 0 {
+		t.Error("Expected beat lines on the highway, got", measures, "measures and", beats, "beats")
+	}
+
+	r := strings.Builder{}
+	model.CreateFretboardView(&r, gpSimpleNoteStyles, nil, nil, gpSimpleBeatLineStyles)
+	if !strings.Contains(r.String(), "───") && !strings.Contains(r.String(), "═══") {
+		t.Error("Expected beat lines to be drawn, got", r.String())
+	}
+}
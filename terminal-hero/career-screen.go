@@ -0,0 +1,215 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/reflow/truncate"
+)
+
+var careerSelectedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(selectedItemColor)).Bold(true)
+var careerLockedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#484a4d"))
+
+// careerScreenModel shows the tiers of the career and the stars earned in each song,
+// and picks a song to play from the unlocked tiers
+type careerScreenModel struct {
+	careerFilePath   string
+	rootPath         string
+	rootSongFolder   *songFolder // the library that the song list already loaded. nil to load it
+	resolvedCareer   *career     // the career from the last visit, which doesn't have to be resolved again
+	settings         *settings
+	db               grDbAccessor
+	career           *career
+	err              error
+	cursor           int    // the index of the highlighted song, counting through every tier
+	highlightPath    string // the song to highlight once the career loads
+	selectedSong     *careerSong
+	backout          bool
+	selectedSongPath string
+}
+
+type careerLoadedMsg struct {
+	career *career
+	err    error
+}
+
+func initialCareerScreenModel(rootPath string, rootSongFolder *songFolder, resolvedCareer *career, db grDbAccessor,
+	settings *settings, highlightPath string) careerScreenModel {
+	careerFilePath := settings.careerFilePath
+	if careerFilePath == "" {
+		var err error
+		careerFilePath, err = getSubDataFolderPath("career.json")
+		if err != nil {
+			return careerScreenModel{settings: settings, err: err}
+		}
+	}
+	return careerScreenModel{
+		careerFilePath: careerFilePath,
+		rootPath:       rootPath,
+		rootSongFolder: rootSongFolder,
+		resolvedCareer: resolvedCareer,
+		settings:       settings,
+		db:             db,
+		highlightPath:  highlightPath,
+	}
+}
+
+// the progress is loaded on every visit, but the career is only resolved the first time, since
+// finding songs by chart hash hashes the whole library
+func loadCareerCmd(careerFilePath string, rootPath string, root *songFolder, resolved *career, profile string, db grDbAccessor) tea.Cmd {
+	return func() tea.Msg {
+		c := resolved
+		if c == nil {
+			cf, err := loadCareerFile(careerFilePath)
+			if err != nil {
+				return careerLoadedMsg{nil, err}
+			}
+			if root == nil {
+				root = loadSongFolder(rootPath)
+			}
+			c = resolveCareer(cf, root)
+		}
+		progress, err := db.getCareerProgress(profile, c.name)
+		if err != nil {
+			return careerLoadedMsg{nil, err}
+		}
+		c.applyProgress(progress)
+		return careerLoadedMsg{c, nil}
+	}
+}
+
+func (m careerScreenModel) Init() tea.Cmd {
+	if m.err != nil {
+		return nil
+	}
+	return loadCareerCmd(m.careerFilePath, m.rootPath, m.rootSongFolder, m.resolvedCareer, m.settings.profile, m.db)
+}
+
+// the tier and song at the index, counting through every tier
+func (c *career) songAt(index int) (*careerTier, *careerSong) {
+	for i := range c.tiers {
+		tier := &c.tiers[i]
+		if index < len(tier.songs) {
+			return tier, &tier.songs[index]
+		}
+		index -= len(tier.songs)
+	}
+	return nil, nil
+}
+
+func (c *career) songCount() int {
+	count := 0
+	for _, tier := range c.tiers {
+		count += len(tier.songs)
+	}
+	return count
+}
+
+func (m careerScreenModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case careerLoadedMsg:
+		m.career = msg.career
+		m.err = msg.err
+		if m.career != nil {
+			for i := 0; i < m.career.songCount(); i++ {
+				if _, cs := m.career.songAt(i); cs.folder != nil && cs.folder.path == m.highlightPath {
+					m.cursor = i
+				}
+			}
+		}
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc", "backspace":
+			m.backout = true
+		case "up", "k", "w":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j", "s":
+			if m.career != nil && m.cursor < m.career.songCount()-1 {
+				m.cursor++
+			}
+		case "enter":
+			if m.career == nil {
+				return m, nil
+			}
+			tier, cs := m.career.songAt(m.cursor)
+			if tier != nil && tier.unlocked && cs.playable() {
+				m.selectedSong = cs
+				m.selectedSongPath = cs.folder.path
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m careerScreenModel) View() string {
+	sb := strings.Builder{}
+	if m.err != nil {
+		sb.WriteString(errorStyle.Render("Couldn't load the career: "+m.err.Error()) + "\n\n")
+		sb.WriteString("The career is read from " + m.careerFilePath + ", which lists songs in tiers:\n\n")
+		sb.WriteString(`{"name": "My Career", "tiers": [` + "\n")
+		sb.WriteString(`  {"name": "Opening Acts", "songsToUnlockNext": 2, "songs": [{"path": "Guitar Hero III/Slow Ride"}, {"chartHash": "b9e7ce09..."}]}` + "\n")
+		sb.WriteString("]}\n\n")
+		sb.WriteString("Press ESC to go back")
+		return sb.String()
+	}
+	if m.career == nil {
+		return "Loading career\n"
+	}
+
+	earned, total := m.career.stars()
+	sb.WriteString(listTitleStyle.Render("Career: "+m.career.name) +
+		fmt.Sprintf("  profile: %s  ", m.settings.profile) + starStyle.Render(fmt.Sprintf("★ %d/%d", earned, total)) + "\n\n")
+
+	lines := []string{}
+	cursorLine := 0
+	index := 0
+	for i, tier := range m.career.tiers {
+		header := fmt.Sprintf("Tier %d: %s  %d/%d passed", i+1, tier.name, tier.passedCount(), len(tier.songs))
+		if i+1 < len(m.career.tiers) {
+			header += fmt.Sprintf(", %d to unlock the next tier", tier.songsToUnlock)
+		}
+		if !tier.unlocked {
+			header = careerLockedStyle.Render(header + "  🔒")
+		}
+		lines = append(lines, performanceHeadlineStyle.Render(header))
+
+		for _, cs := range tier.songs {
+			name := truncate.StringWithTail(cs.name(), 50, "...")
+			var status string
+			if !cs.playable() {
+				status = errorStyle.Render("not in the library")
+			} else if !tier.unlocked {
+				status = careerLockedStyle.Render("locked")
+			} else if cs.passed {
+				status = starStyle.Render(smallStarString(cs.stars))
+			} else {
+				status = "not passed"
+			}
+
+			line := "    " + lipgloss.NewStyle().Width(52).Render(name) + status
+			if index == m.cursor {
+				line = careerSelectedStyle.Render("  > "+lipgloss.NewStyle().Width(52).Render(name)) + status
+				cursorLine = len(lines)
+			}
+			lines = append(lines, line)
+			index++
+		}
+		lines = append(lines, "")
+	}
+
+	// scroll so that the highlighted song is on screen
+	height := max(m.settings.windowHeight-8, 5)
+	start := 0
+	if len(lines) > height {
+		start = min(max(cursorLine-height/2, 0), len(lines)-height)
+	}
+	end := min(start+height, len(lines))
+	sb.WriteString(strings.Join(lines[start:end], "\n"))
+
+	sb.WriteString("\n\nenter play • esc back to the song list")
+	return sb.String()
+}
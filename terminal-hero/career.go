@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// the career file lists songs in tiers. passing enough songs in a tier unlocks the next one
+type careerFile struct {
+	Name  string           `json:"name"`
+	Tiers []careerFileTier `json:"tiers"`
+}
+
+type careerFileTier struct {
+	Name string `json:"name"`
+	// how many songs have to be passed to unlock the next tier. 0 means all of them
+	SongsToUnlockNext int             `json:"songsToUnlockNext"`
+	Songs             []careerSongRef `json:"songs"`
+}
+
+// a song is found by its chart hash, or by its path in the Songs folder
+type careerSongRef struct {
+	ChartHash string `json:"chartHash,omitempty"`
+	Path      string `json:"path,omitempty"`
+}
+
+func loadCareerFile(filePath string) (careerFile, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return careerFile{}, err
+	}
+
+	var cf careerFile
+	err = json.Unmarshal(data, &cf)
+	if err != nil {
+		return careerFile{}, fmt.Errorf("invalid career file %s: %w", filePath, err)
+	}
+	if cf.Name == "" {
+		return careerFile{}, errors.New("the career has no name")
+	}
+	if len(cf.Tiers) == 0 {
+		return careerFile{}, errors.New("the career has no tiers")
+	}
+	for i, tier := range cf.Tiers {
+		if len(tier.Songs) == 0 {
+			return careerFile{}, fmt.Errorf("tier %d has no songs", i+1)
+		}
+		for _, ref := range tier.Songs {
+			if ref.ChartHash == "" && ref.Path == "" {
+				return careerFile{}, fmt.Errorf("a song in tier %d has no chartHash or path", i+1)
+			}
+		}
+	}
+	return cf, nil
+}
+
+type career struct {
+	name  string
+	tiers []careerTier
+}
+
+type careerTier struct {
+	name          string
+	songsToUnlock int // how many songs have to be passed to unlock the next tier
+	songs         []careerSong
+	unlocked      bool
+}
+
+type careerSong struct {
+	ref       careerSongRef
+	folder    *songFolder // nil if the song isn't in the library
+	chartHash string
+	stars     int
+	passed    bool
+}
+
+// the folder's name, or what the career file says when the song isn't in the library
+func (cs careerSong) name() string {
+	if cs.folder != nil {
+		return cs.folder.name
+	} else if cs.ref.Path != "" {
+		return filepath.Base(cs.ref.Path)
+	}
+	return cs.ref.ChartHash
+}
+
+func (cs careerSong) playable() bool {
+	return cs.folder != nil
+}
+
+// finds the career's songs in the library. every chart is only hashed if a song is listed by chart hash
+func resolveCareer(cf careerFile, root *songFolder) *career {
+	c := &career{name: cf.Name}
+	var songsByHash map[string]*songFolder
+	for _, ft := range cf.Tiers {
+		tier := careerTier{name: ft.Name, songsToUnlock: len(ft.Songs)}
+		if ft.SongsToUnlockNext > 0 {
+			tier.songsToUnlock = min(ft.SongsToUnlockNext, len(ft.Songs))
+		}
+
+		for _, ref := range ft.Songs {
+			cs := careerSong{ref: ref, chartHash: ref.ChartHash}
+			if ref.ChartHash != "" {
+				if songsByHash == nil {
+					songsByHash = hashAllSongs(root)
+				}
+				cs.folder = songsByHash[ref.ChartHash]
+			} else {
+				f := root.queryFolder(splitFolderPath(ref.Path))
+				if f != nil && f.isLeaf {
+					if ch, err := f.songChartHash(); err == nil {
+						cs.folder = f
+						cs.chartHash = ch
+					}
+				}
+			}
+			tier.songs = append(tier.songs, cs)
+		}
+		c.tiers = append(c.tiers, tier)
+	}
+	return c
+}
+
+// sets which songs have been passed from the stars of each chart hash, and unlocks the tiers
+// after the ones that have had enough songs passed
+func (c *career) applyProgress(progress map[string]int) {
+	unlocked := true
+	for i := range c.tiers {
+		tier := &c.tiers[i]
+		tier.unlocked = unlocked
+		for j := range tier.songs {
+			cs := &tier.songs[j]
+			cs.stars, cs.passed = progress[cs.chartHash]
+			if cs.chartHash == "" {
+				cs.passed = false
+			}
+		}
+		unlocked = unlocked && tier.passedCount() >= tier.songsToUnlock
+	}
+}
+
+func (tier careerTier) passedCount() int {
+	count := 0
+	for _, cs := range tier.songs {
+		if cs.passed {
+			count++
+		}
+	}
+	return count
+}
+
+// the stars earned and the most stars that could be earned
+func (c *career) stars() (int, int) {
+	earned, total := 0, 0
+	for _, tier := range c.tiers {
+		for _, cs := range tier.songs {
+			earned += cs.stars
+			total += 5
+		}
+	}
+	return earned, total
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestCareerFile(t *testing.T, text string) string {
+	filePath := filepath.Join(t.TempDir(), "career.json")
+	err := os.WriteFile(filePath, []byte(text), 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return filePath
+}
+
+func TestLoadCareerFile_RejectsSongsWithoutAHashOrPath(t *testing.T) {
+	filePath := writeTestCareerFile(t, `{"name": "Test", "tiers": [{"name": "One", "songs": [{}]}]}`)
+	_, err := loadCareerFile(filePath)
+	if err == nil {
+		t.Error("Expected an error for a song without a chartHash or path")
+	}
+}
+
+func TestCareer_UnlocksTiers(t *testing.T) {
+	rootPath := t.TempDir()
+	oneHash := writeTestSongFolder(t, filepath.Join(rootPath, "GH3", "One"), "one")
+	slowRideHash := writeTestSongFolder(t, filepath.Join(rootPath, "GH3", "Slow Ride"), "slow ride")
+	writeTestSongFolder(t, filepath.Join(rootPath, "RB", "Anthem"), "anthem")
+
+	filePath := writeTestCareerFile(t, `{"name": "Test", "tiers": [
+		{"name": "Openers", "songsToUnlockNext": 1, "songs": [{"path": "GH3/One"}, {"chartHash": "`+slowRideHash+`"}]},
+		{"name": "Headliners", "songs": [{"path": "RB/Anthem"}, {"path": "RB/Deleted"}]},
+		{"name": "Encore", "songs": [{"path": "GH3/One"}]}
+	]}`)
+	cf, err := loadCareerFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := resolveCareer(cf, loadSongFolder(rootPath))
+	openers := c.tiers[0]
+	if openers.songs[0].chartHash != oneHash || openers.songs[1].folder == nil || openers.songs[1].name() != "Slow Ride" {
+		t.Fatal("Expected the songs to be found by path and by chart hash, got", openers.songs)
+	}
+	if c.tiers[1].songs[1].playable() {
+		t.Error("Expected a song that isn't in the library to not be playable")
+	}
+
+	c.applyProgress(map[string]int{})
+	if !c.tiers[0].unlocked || c.tiers[1].unlocked {
+		t.Error("Expected only the first tier to be unlocked")
+	}
+
+	// passing one song unlocks the headliners, but every song has to be passed to unlock the encore
+	c.applyProgress(map[string]int{slowRideHash: 4})
+	if !c.tiers[1].unlocked || c.tiers[2].unlocked {
+		t.Error("Expected the second tier to be unlocked, and not the third")
+	}
+	if earned, total := c.stars(); earned != 4 || total != 25 {
+		t.Errorf("Expected 4/25 stars, got %d/%d", earned, total)
+	}
+}
+
+func TestLoadCareerCmd_ReusesTheResolvedCareer(t *testing.T) {
+	rootPath := t.TempDir()
+	oneHash := writeTestSongFolder(t, filepath.Join(rootPath, "GH3", "One"), "one")
+	filePath := writeTestCareerFile(t, `{"name": "Test", "tiers": [{"name": "Openers", "songs": [{"chartHash": "`+oneHash+`"}]}]}`)
+
+	db, err := openAndMigrateTestDb()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.destroy(t)
+
+	first := loadCareerCmd(filePath, rootPath, nil, nil, "default", db)().(careerLoadedMsg)
+	if first.err != nil {
+		t.Fatal(first.err)
+	}
+
+	// the career file isn't read again, but the progress is
+	err = os.Remove(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.setCareerStars("default", "Test", oneHash, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second := loadCareerCmd(filePath, rootPath, nil, first.career, "default", db)().(careerLoadedMsg)
+	if second.err != nil {
+		t.Fatal(second.err)
+	}
+	if second.career != first.career || !second.career.tiers[0].songs[0].passed {
+		t.Error("Expected the same career with the song passed, got", second.career)
+	}
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var editorCursorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(selectedItemColor)).Bold(true)
+var editorMeasureStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(yellowAccentColor))
+var editorBeatStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#5c5c5c"))
+var editorSyncStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(pinkAccentColor))
+var editorHelpStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#90918e"))
+
+type editorCell struct {
+	note      bool
+	sustain   bool
+	openNote  bool
+	syncLabel string
+}
+
+func (m chartEditorModel) visibleRows() int {
+	rows := m.settings.fretBoardHeight - 4
+	if rows < 10 {
+		return 10
+	}
+	return rows
+}
+
+func (m chartEditorModel) cursorRow() int {
+	return m.visibleRows() - 5
+}
+
+// the tick at the start of the row. rows above the cursor are later in the song
+func (m chartEditorModel) rowTick(row int) int {
+	return m.cursorTick + (m.cursorRow()-row)*m.gridStepTicks()
+}
+
+// the row that the tick is displayed in, which may be outside of the visible rows
+func (m chartEditorModel) tickRow(tick int) int {
+	step := m.gridStepTicks()
+	diff := tick - m.cursorTick
+	rowsFromCursor := diff / step
+	if diff < 0 && diff%step != 0 {
+		rowsFromCursor--
+	}
+	return m.cursorRow() - rowsFromCursor
+}
+
+// the lane that the note is shown in. ok is false for notes that aren't shown in a lane
+func (m chartEditorModel) noteLane(rawNoteType int) (int, bool) {
+	if m.isDrums() {
+		// for drums, 0 is the kick pedal and the cymbal markers are above 5
+		lane := rawNoteType - 1
+		return lane, lane >= 0 && lane < m.laneCount()
+	}
+	lane, _, ok := laneForNoteType(m.chartInfo.track, rawNoteType)
+	return lane, ok
+}
+
+// the styles for each lane of the track
+func (m chartEditorModel) noteStyles() []*lipgloss.Style {
+	if m.laneCount() == sixFretLaneCount {
+		return gpSixFretNoteStyles
+	}
+	return gpNoteStyles
+}
+
+func (m chartEditorModel) createEditorCells() ([][]editorCell, []editorCell) {
+	rows := m.visibleRows()
+	laneCells := make([][]editorCell, rows)
+	for row := range laneCells {
+		laneCells[row] = make([]editorCell, m.laneCount())
+	}
+	rowCells := make([]editorCell, rows)
+
+	for _, note := range m.trackNotes() {
+		startRow := m.tickRow(note.TimeStamp)
+		endRow := m.tickRow(note.TimeStamp + int(note.ExtraData))
+		if startRow < 0 {
+			// notes after the top of the screen
+			break
+		}
+
+		if note.RawNoteType == m.openNoteType() {
+			if startRow < rows {
+				rowCells[startRow].openNote = true
+			}
+			continue
+		}
+
+		lane, ok := m.noteLane(note.RawNoteType)
+		if !ok {
+			continue
+		}
+
+		if startRow < rows {
+			laneCells[startRow][lane].note = true
+		}
+		for row := startRow - 1; row >= endRow && row >= 0; row-- {
+			if row < rows {
+				laneCells[row][lane].sustain = true
+			}
+		}
+	}
+
+	for _, sync := range m.chart.SyncTrack {
+		row := m.tickRow(sync.TimeStamp)
+		if row < 0 || row >= rows {
+			continue
+		}
+		label := "TS " + strconv.Itoa(sync.Value)
+		if denominator, ok := m.chart.TimeSignatureDenominators[sync.TimeStamp]; ok {
+			label += "/" + strconv.Itoa(1<<denominator)
+		}
+		if sync.Type == "B" {
+			label = "BPM " + formatBpm(sync.Value)
+		}
+		if rowCells[row].syncLabel != "" {
+			rowCells[row].syncLabel += "  "
+		}
+		rowCells[row].syncLabel += label
+	}
+
+	return laneCells, rowCells
+}
+
+func (m chartEditorModel) createHighwayView(r *strings.Builder) {
+	laneCells, rowCells := m.createEditorCells()
+	noteStyles := m.noteStyles()
+	cursorRow := m.cursorRow()
+	resolution := m.resolution()
+
+	for row := range laneCells {
+		tick := m.rowTick(row)
+		if tick < 0 {
+			r.WriteString("\n")
+			continue
+		}
+
+		measure, beat, offset := beatPosition(m.chart.SyncTrack, resolution, tick)
+		if offset == 0 && beat == 0 {
+			r.WriteString(editorMeasureStyle.Render(fmt.Sprintf("%4d ═", measure+1)))
+		} else if offset == 0 {
+			r.WriteString(editorBeatStyle.Render("     ·"))
+		} else {
+			r.WriteString("      ")
+		}
+
+		if row == cursorRow {
+			r.WriteString(editorCursorStyle.Render(" ▶"))
+		} else {
+			r.WriteString("  ")
+		}
+		r.WriteString("| ")
+
+		for lane, cell := range laneCells[row] {
+			separator := " "
+			if row == cursorRow {
+				separator = "-"
+			}
+			r.WriteString(separator)
+
+			noteStyle := noteStyles[lane]
+			if cell.note {
+				writeStyledString(r, noteStyle, "("+laneLabel(m.laneCount(), lane)+")")
+			} else if rowCells[row].openNote {
+				writeStyledString(r, &gpOpenNoteStyle, "---")
+			} else if cell.sustain {
+				writeStyledString(r, noteStyle, " | ")
+			} else if row == cursorRow {
+				writeStyledString(r, noteStyle, "---")
+			} else {
+				r.WriteString("   ")
+			}
+
+			r.WriteString(separator)
+		}
+
+		r.WriteString(" |")
+		if rowCells[row].syncLabel != "" {
+			r.WriteString(" " + editorSyncStyle.Render(rowCells[row].syncLabel))
+		}
+		r.WriteRune('\n')
+	}
+}
+
+func (m chartEditorModel) View() string {
+	r := strings.Builder{}
+
+	title := fmt.Sprintf("Editing %s - %s", m.chartInfo.songName(), m.chartInfo.track.fullTrackName)
+	if m.dirty {
+		title += " *"
+	}
+	r.WriteString(listTitleStyle.Render(title) + "\n")
+
+	m.createHighwayView(&r)
+
+	measure, beat, offset := beatPosition(m.chart.SyncTrack, m.resolution(), m.cursorTick)
+	r.WriteString(fmt.Sprintf("Tick %d  Measure %d Beat %d+%d  Grid 1/%d beat  %s",
+		m.cursorTick, measure+1, beat+1, offset, editorGridDivisions[m.gridIndex],
+		formatMs(NewTempoMap(m.chart).TickToMs(m.cursorTick))))
+	r.WriteRune('\n')
+
+	if m.prompt != nil {
+		r.WriteString(m.prompt.View())
+	} else if m.statusMsg != "" {
+		r.WriteString(m.statusMsg)
+	} else {
+		noteKeys := "1-5"
+		if m.laneCount() == sixFretLaneCount {
+			noteKeys = sixFretKeys
+		}
+		r.WriteString(editorHelpStyle.Render("↑/↓ move  [/] grid  " + noteKeys + " note  0 open  +/- sustain  x delete  b BPM  t TS  space play  ctrl+s save  esc exit"))
+	}
+
+	return r.String()
+}
+
+func formatMs(ms float64) string {
+	totalSeconds := int(ms / 1000)
+	return fmt.Sprintf("%d:%02d.%03d", totalSeconds/60, totalSeconds%60, int(ms)%1000)
+}
@@ -0,0 +1,606 @@
+package main
+
+import (
+	"fmt"
+	"math/bits"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/effects"
+	"github.com/faiface/beep/speaker"
+)
+
+// chartEditorModel is the model responsible for:
+// - displaying a single track of a chart as a highway that can be scrolled through
+// - placing and deleting notes, chords and sustains at the cursor
+// - editing BPM and time signature changes in the SyncTrack
+// - playing the song audio from the cursor
+// - saving the chart back to notes.chart
+type chartEditorModel struct {
+	chart      *Chart
+	chartInfo  chartInfo
+	settings   *settings
+	speaker    soundPlayer
+	songSounds songSounds
+
+	cursorTick int
+	gridIndex  int // index into editorGridDivisions
+
+	dirty          bool
+	confirmingExit bool
+	exit           bool
+	statusMsg      string
+
+	// prompt for entering BPM and time signature values
+	prompt     *textinput.Model
+	promptKind editorPromptKind
+
+	playing         bool
+	playStartTime   time.Time
+	playStartTimeMs float64
+}
+
+type editorPromptKind int
+
+const (
+	epNone editorPromptKind = iota
+	epBpm
+	epTimeSignature
+)
+
+type editorTickMsg time.Time
+
+const editorTickTime = 30 * time.Millisecond
+
+// number of grid lines per beat that the cursor can move by
+var editorGridDivisions = []int{1, 2, 3, 4, 6, 8, 12, 16, 24, 32}
+
+// the index of the default grid division (4 = 16th notes)
+const defaultEditorGridIndex = 3
+
+const (
+	guitarOpenNoteType = 7
+	drumsKickNoteType  = 0
+)
+
+func initialChartEditorModel(lm loadSongModel, stngs *settings) chartEditorModel {
+	return chartEditorModel{
+		chart: lm.chart.chart,
+		chartInfo: chartInfo{
+			fullFolderPath: lm.chartFolderPath,
+			track:          *lm.selectedTrack,
+		},
+		settings:   stngs,
+		speaker:    lm.speaker,
+		songSounds: lm.songSounds.songSounds,
+		gridIndex:  defaultEditorGridIndex,
+	}
+}
+
+func (m chartEditorModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m chartEditorModel) isDrums() bool {
+	return m.chartInfo.track.instrument == instrumentDrums
+}
+
+func (m chartEditorModel) laneCount() int {
+	return laneCountForTrack(m.chartInfo.track)
+}
+
+func (m chartEditorModel) trackNotes() []Note {
+	return m.chart.Tracks[m.chartInfo.track.fullTrackName]
+}
+
+func (m chartEditorModel) setTrackNotes(notes []Note) chartEditorModel {
+	m.chart.Tracks[m.chartInfo.track.fullTrackName] = notes
+	m.dirty = true
+	m.confirmingExit = false
+	return m
+}
+
+// the raw note type in the chart for the lane
+func (m chartEditorModel) laneNoteType(lane int) int {
+	if m.isDrums() {
+		// for drums, 0 is the kick pedal
+		return lane + 1
+	}
+	return noteTypeForLane(m.chartInfo.track, lane)
+}
+
+func (m chartEditorModel) openNoteType() int {
+	if m.isDrums() {
+		return drumsKickNoteType
+	}
+	return guitarOpenNoteType
+}
+
+// the number of ticks between grid lines
+func (m chartEditorModel) gridStepTicks() int {
+	step := m.resolution() / editorGridDivisions[m.gridIndex]
+	if step < 1 {
+		return 1
+	}
+	return step
+}
+
+func (m chartEditorModel) resolution() int {
+	if m.chart.SongMetadata.Resolution <= 0 {
+		return 192
+	}
+	return m.chart.SongMetadata.Resolution
+}
+
+func (m chartEditorModel) measureTicks() int {
+	tsTick, numerator := timeSignatureAt(m.chart.SyncTrack, m.cursorTick)
+	denominator, ok := m.chart.TimeSignatureDenominators[tsTick]
+	if !ok {
+		denominator = 2
+	}
+	return numerator * beatTicksForDenominator(m.resolution(), denominator)
+}
+
+func nextGridTick(tick int, step int) int {
+	return (tick/step + 1) * step
+}
+
+func prevGridTick(tick int, step int) int {
+	if tick%step != 0 {
+		return tick - tick%step
+	}
+	if tick-step < 0 {
+		return 0
+	}
+	return tick - step
+}
+
+func (m chartEditorModel) lastNoteTick() int {
+	notes := m.trackNotes()
+	if len(notes) == 0 {
+		return 0
+	}
+	return notes[len(notes)-1].TimeStamp
+}
+
+func (m chartEditorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case editorTickMsg:
+		if !m.playing {
+			return m, nil
+		}
+		elapsedMs := float64(time.Time(msg).Sub(m.playStartTime) / time.Millisecond)
+		m.cursorTick = int(NewTempoMap(m.chart).MsToTick(m.playStartTimeMs + elapsedMs))
+		return m, editorTimerCmd()
+	case tea.KeyMsg:
+		if m.prompt != nil {
+			return m.updatePrompt(msg)
+		}
+		return m.updateKey(msg)
+	default:
+		if m.prompt != nil {
+			ti, cmd := m.prompt.Update(msg)
+			m.prompt = &ti
+			return m, cmd
+		}
+	}
+	return m, nil
+}
+
+func editorTimerCmd() tea.Cmd {
+	return tea.Tick(editorTickTime, func(t time.Time) tea.Msg {
+		return editorTickMsg(t)
+	})
+}
+
+func (m chartEditorModel) updateKey(msg tea.KeyMsg) (chartEditorModel, tea.Cmd) {
+	keyName := msg.String()
+
+	if m.playing {
+		m = m.stopPlayback()
+		if keyName == "space" || keyName == " " {
+			return m, nil
+		}
+	}
+
+	if keyName != "esc" {
+		m.confirmingExit = false
+	}
+	m.statusMsg = ""
+
+	step := m.gridStepTicks()
+
+	// the lane keys come first, since six-fret tracks use some of the letter keys
+	if lane, ok := laneForKey(m.laneCount(), keyName); ok {
+		m = m.setTrackNotes(toggleNote(m.trackNotes(), m.cursorTick, m.laneNoteType(lane)))
+		return m, nil
+	}
+
+	switch keyName {
+	case "up", "k", "w":
+		m.cursorTick = nextGridTick(m.cursorTick, step)
+	case "down", "j", "s":
+		m.cursorTick = prevGridTick(m.cursorTick, step)
+	case "pgup":
+		m.cursorTick += m.measureTicks()
+	case "pgdown":
+		m.cursorTick -= m.measureTicks()
+		if m.cursorTick < 0 {
+			m.cursorTick = 0
+		}
+	case "home":
+		m.cursorTick = 0
+	case "end":
+		m.cursorTick = m.lastNoteTick()
+	case "[":
+		if m.gridIndex > 0 {
+			m.gridIndex--
+		}
+	case "]":
+		if m.gridIndex < len(editorGridDivisions)-1 {
+			m.gridIndex++
+		}
+	case "0":
+		m = m.setTrackNotes(toggleNote(m.trackNotes(), m.cursorTick, m.openNoteType()))
+	case "x", "delete":
+		m = m.setTrackNotes(removeNotesAt(m.trackNotes(), m.cursorTick))
+	case "+", "=":
+		m = m.setTrackNotes(changeSustainAt(m.trackNotes(), m.cursorTick, step))
+	case "-", "_":
+		m = m.setTrackNotes(changeSustainAt(m.trackNotes(), m.cursorTick, -step))
+	case "b":
+		return m.openPrompt(epBpm)
+	case "t":
+		return m.openPrompt(epTimeSignature)
+	case "space", " ":
+		return m.startPlayback()
+	case "ctrl+s":
+		m = m.save()
+	case "esc":
+		if m.dirty && !m.confirmingExit {
+			m.confirmingExit = true
+			m.statusMsg = "Unsaved changes. Press ESC again to discard them or CTRL+S to save"
+		} else {
+			m.exit = true
+		}
+	}
+	return m, nil
+}
+
+func (m chartEditorModel) openPrompt(kind editorPromptKind) (chartEditorModel, tea.Cmd) {
+	ti := textinput.New()
+	ti.CharLimit = 10
+	ti.Width = 12
+	switch kind {
+	case epBpm:
+		ti.Prompt = "BPM: "
+		ti.Placeholder = "120.000"
+	case epTimeSignature:
+		ti.Prompt = "Time signature: "
+		ti.Placeholder = "4/4"
+	}
+	ti.Focus()
+	m.prompt = &ti
+	m.promptKind = kind
+	return m, textinput.Blink
+}
+
+func (m chartEditorModel) updatePrompt(msg tea.KeyMsg) (chartEditorModel, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.prompt = nil
+		return m, nil
+	case "enter":
+		value := strings.TrimSpace(m.prompt.Value())
+		m = m.applyPrompt(value)
+		m.prompt = nil
+		return m, nil
+	}
+	ti, cmd := m.prompt.Update(msg)
+	m.prompt = &ti
+	return m, cmd
+}
+
+// applies the value entered in the prompt. an empty value removes the
+// sync track element at the cursor
+func (m chartEditorModel) applyPrompt(value string) chartEditorModel {
+	syncType := "B"
+	if m.promptKind == epTimeSignature {
+		syncType = "TS"
+	}
+
+	if value == "" {
+		if syncType == "B" && m.cursorTick == 0 {
+			m.statusMsg = "The BPM at the start of the song can't be removed"
+			return m
+		}
+		m.chart.SyncTrack = removeSyncTrackElement(m.chart.SyncTrack, m.cursorTick, syncType)
+		if syncType == "TS" {
+			delete(m.chart.TimeSignatureDenominators, m.cursorTick)
+		}
+		m.dirty = true
+		return m
+	}
+
+	var syncValue int
+	if syncType == "B" {
+		bpm, err := strconv.ParseFloat(value, 64)
+		if err != nil || bpm <= 0 {
+			m.statusMsg = "Invalid BPM: " + value
+			return m
+		}
+		// BPM values are stored with 3 decimal places
+		syncValue = int(bpm*1000 + 0.5)
+	} else {
+		numerator, denominator, ok := parseTimeSignature(value)
+		if !ok {
+			m.statusMsg = "Invalid time signature: " + value
+			return m
+		}
+		syncValue = numerator
+		m = m.setTimeSignatureDenominator(denominator)
+	}
+
+	m.chart.SyncTrack = setSyncTrackElement(m.chart.SyncTrack, m.cursorTick, syncType, syncValue)
+	m.dirty = true
+	return m
+}
+
+// parses "7" or "7/8". the denominator is returned as the power of 2 that the chart stores,
+// so a quarter note is 2
+func parseTimeSignature(value string) (numerator int, denominator int, ok bool) {
+	numeratorText, denominatorText, hasDenominator := strings.Cut(value, "/")
+	numerator, err := strconv.Atoi(strings.TrimSpace(numeratorText))
+	if err != nil || numerator <= 0 {
+		return 0, 0, false
+	}
+	if !hasDenominator {
+		return numerator, 2, true
+	}
+	noteValue, err := strconv.Atoi(strings.TrimSpace(denominatorText))
+	if err != nil || noteValue <= 0 || noteValue&(noteValue-1) != 0 {
+		return 0, 0, false
+	}
+	denominator = bits.TrailingZeros(uint(noteValue))
+	if denominator > 8 {
+		return 0, 0, false
+	}
+	return numerator, denominator, true
+}
+
+// quarter notes are the default, so they aren't written to the chart
+func (m chartEditorModel) setTimeSignatureDenominator(denominator int) chartEditorModel {
+	if denominator == 2 {
+		delete(m.chart.TimeSignatureDenominators, m.cursorTick)
+		return m
+	}
+	if m.chart.TimeSignatureDenominators == nil {
+		m.chart.TimeSignatureDenominators = make(map[int]int)
+	}
+	m.chart.TimeSignatureDenominators[m.cursorTick] = denominator
+	return m
+}
+
+func (m chartEditorModel) save() chartEditorModel {
+	chartFilePath := filepath.Join(m.chartInfo.fullFolderPath, "notes.chart")
+	err := saveChartFile(chartFilePath, m.chart)
+	if err != nil {
+		log.Error("failed to save chart", "path", chartFilePath, "err", err)
+		m.statusMsg = "Failed to save: " + err.Error()
+		return m
+	}
+	m.dirty = false
+	m.statusMsg = "Saved " + chartFilePath
+	return m
+}
+
+func (m chartEditorModel) hasAudio() bool {
+	return m.songSounds.song.soundStream != nil || m.songSounds.guitar.soundStream != nil ||
+		m.songSounds.bass.soundStream != nil || m.songSounds.drums.soundStream != nil ||
+		m.songSounds.keys.soundStream != nil || m.songSounds.rhythm.soundStream != nil
+}
+
+// plays the song audio starting at the cursor and moves the cursor along with it
+func (m chartEditorModel) startPlayback() (chartEditorModel, tea.Cmd) {
+	m.playStartTimeMs = NewTempoMap(m.chart).TickToMs(m.cursorTick)
+	m.playStartTime = time.Now()
+	m.playing = true
+
+	if !m.hasAudio() {
+		m.statusMsg = "No audio loaded for this song"
+		return m, editorTimerCmd()
+	}
+
+	speaker.Lock()
+	seekStem(m.songSounds.song.soundStream, m.songSounds.song.format, m.playStartTimeMs)
+	seekStem(volumeStreamSeeker(m.songSounds.guitar.soundStream), m.songSounds.guitar.format, m.playStartTimeMs)
+	seekStem(volumeStreamSeeker(m.songSounds.bass.soundStream), m.songSounds.bass.format, m.playStartTimeMs)
+	seekStem(volumeStreamSeeker(m.songSounds.drums.soundStream), m.songSounds.drums.format, m.playStartTimeMs)
+	seekStem(volumeStreamSeeker(m.songSounds.keys.soundStream), m.songSounds.keys.format, m.playStartTimeMs)
+	seekStem(volumeStreamSeeker(m.songSounds.rhythm.soundStream), m.songSounds.rhythm.format, m.playStartTimeMs)
+	speaker.Unlock()
+
+	mixed := mixSounds(convToStandardSound(m.songSounds.song), convToStandardSound(m.songSounds.guitar),
+		convToStandardSound(m.songSounds.bass), convToStandardSound(m.songSounds.drums),
+		convToStandardSound(m.songSounds.keys), convToStandardSound(m.songSounds.rhythm))
+	m.speaker.play(mixed.soundStream, mixed.format)
+
+	return m, editorTimerCmd()
+}
+
+func (m chartEditorModel) stopPlayback() chartEditorModel {
+	m.playing = false
+	if m.hasAudio() {
+		m.speaker.clear()
+	}
+	m.cursorTick = prevGridTick(m.cursorTick+1, m.gridStepTicks())
+	return m
+}
+
+func (m chartEditorModel) destroy() {
+	if m.playing && m.hasAudio() {
+		m.speaker.clear()
+	}
+	m.songSounds.close()
+}
+
+func volumeStreamSeeker(vol *effects.Volume) beep.StreamSeeker {
+	if vol == nil {
+		return nil
+	}
+	ss, _ := vol.Streamer.(beep.StreamSeeker)
+	return ss
+}
+
+// seeks the stream to the time. should be called while the speaker is locked
+func seekStem(stream beep.StreamSeeker, format beep.Format, timeMs float64) {
+	if stream == nil {
+		return
+	}
+	pos := format.SampleRate.N(time.Duration(timeMs * float64(time.Millisecond)))
+	if pos > stream.Len() {
+		pos = stream.Len()
+	}
+	if pos < 0 {
+		pos = 0
+	}
+	err := stream.Seek(pos)
+	if err != nil {
+		log.Error("failed to seek stem", "err", err)
+	}
+}
+
+// finds the index that a note with the tick and note type should be inserted at
+// to keep the notes sorted
+func noteInsertIndex(notes []Note, tick int, noteType int) int {
+	return sort.Search(len(notes), func(i int) bool {
+		if notes[i].TimeStamp != tick {
+			return notes[i].TimeStamp > tick
+		}
+		return notes[i].RawNoteType >= noteType
+	})
+}
+
+// adds a note at the tick if it doesn't exist, otherwise removes it
+func toggleNote(notes []Note, tick int, noteType int) []Note {
+	i := noteInsertIndex(notes, tick, noteType)
+	if i < len(notes) && notes[i].TimeStamp == tick && notes[i].RawNoteType == noteType {
+		return append(notes[:i:i], notes[i+1:]...)
+	}
+
+	result := make([]Note, 0, len(notes)+1)
+	result = append(result, notes[:i]...)
+	result = append(result, Note{tick, noteType, 0})
+	return append(result, notes[i:]...)
+}
+
+// removes every note (chords included) at the tick
+func removeNotesAt(notes []Note, tick int) []Note {
+	result := make([]Note, 0, len(notes))
+	for _, note := range notes {
+		if note.TimeStamp != tick {
+			result = append(result, note)
+		}
+	}
+	return result
+}
+
+// lengthens or shortens the sustain of every note at the tick
+func changeSustainAt(notes []Note, tick int, deltaTicks int) []Note {
+	result := append([]Note{}, notes...)
+	for i, note := range result {
+		if note.TimeStamp == tick {
+			sustain := note.ExtraData + int64(deltaTicks)
+			if sustain < 0 {
+				sustain = 0
+			}
+			result[i].ExtraData = sustain
+		}
+	}
+	return result
+}
+
+// sets the value of the sync track element of the type at the tick,
+// adding the element if it doesn't exist
+func setSyncTrackElement(syncTrack []SyncTrackElement, tick int, syncType string, value int) []SyncTrackElement {
+	insertIndex := len(syncTrack)
+	for i, sync := range syncTrack {
+		if sync.TimeStamp == tick && sync.Type == syncType {
+			result := append([]SyncTrackElement{}, syncTrack...)
+			result[i].Value = value
+			return result
+		}
+		if sync.TimeStamp > tick {
+			insertIndex = i
+			break
+		}
+	}
+
+	result := make([]SyncTrackElement, 0, len(syncTrack)+1)
+	result = append(result, syncTrack[:insertIndex]...)
+	result = append(result, SyncTrackElement{tick, syncType, value})
+	return append(result, syncTrack[insertIndex:]...)
+}
+
+func removeSyncTrackElement(syncTrack []SyncTrackElement, tick int, syncType string) []SyncTrackElement {
+	result := make([]SyncTrackElement, 0, len(syncTrack))
+	for _, sync := range syncTrack {
+		if sync.TimeStamp != tick || sync.Type != syncType {
+			result = append(result, sync)
+		}
+	}
+	return result
+}
+
+// gets the tick of the time signature change in effect at the tick and its beats per measure
+func timeSignatureAt(syncTrack []SyncTrackElement, tick int) (int, int) {
+	tsTick := 0
+	numerator := 4
+	for _, sync := range syncTrack {
+		if sync.TimeStamp > tick {
+			break
+		}
+		if sync.Type == "TS" && sync.Value > 0 {
+			tsTick = sync.TimeStamp
+			numerator = sync.Value
+		}
+	}
+	return tsTick, numerator
+}
+
+// gets the measure (0-based), beat within the measure, and ticks past the beat for the tick
+func beatPosition(syncTrack []SyncTrackElement, resolution int, tick int) (measure int, beat int, offset int) {
+	segmentStart := 0
+	numerator := 4
+	for _, sync := range syncTrack {
+		if sync.TimeStamp > tick {
+			break
+		}
+		if sync.Type != "TS" || sync.Value <= 0 {
+			continue
+		}
+		beats := (sync.TimeStamp - segmentStart) / resolution
+		// a partial measure before a time signature change still counts as a measure
+		measure += (beats + numerator - 1) / numerator
+		segmentStart = sync.TimeStamp
+		numerator = sync.Value
+	}
+
+	ticksIntoSegment := tick - segmentStart
+	beats := ticksIntoSegment / resolution
+	measure += beats / numerator
+	beat = beats % numerator
+	offset = ticksIntoSegment % resolution
+	return measure, beat, offset
+}
+
+func formatBpm(value int) string {
+	return fmt.Sprintf("%.3f", float64(value)/1000)
+}
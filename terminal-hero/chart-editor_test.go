@@ -0,0 +1,314 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestToggleNote_AddsNotesInOrder(t *testing.T) {
+	notes := []Note{{0, 0, 0}, {192, 2, 0}}
+
+	notes = toggleNote(notes, 96, 1)
+	notes = toggleNote(notes, 192, 0)
+	notes = toggleNote(notes, 384, 4)
+
+	expected := []Note{{0, 0, 0}, {96, 1, 0}, {192, 0, 0}, {192, 2, 0}, {384, 4, 0}}
+	if !reflect.DeepEqual(expected, notes) {
+		t.Errorf("Expected %v, got %v", expected, notes)
+	}
+}
+
+func TestToggleNote_RemovesExistingNote(t *testing.T) {
+	notes := []Note{{0, 0, 0}, {192, 0, 0}, {192, 2, 0}}
+
+	result := toggleNote(notes, 192, 0)
+
+	expected := []Note{{0, 0, 0}, {192, 2, 0}}
+	if !reflect.DeepEqual(expected, result) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+
+	// the original notes should not be modified
+	if len(notes) != 3 || notes[1] != (Note{192, 0, 0}) {
+		t.Error("Expected original notes to be unchanged, got", notes)
+	}
+}
+
+func TestRemoveNotesAt_RemovesChord(t *testing.T) {
+	notes := []Note{{0, 0, 0}, {192, 0, 0}, {192, 2, 0}, {384, 1, 0}}
+
+	result := removeNotesAt(notes, 192)
+
+	expected := []Note{{0, 0, 0}, {384, 1, 0}}
+	if !reflect.DeepEqual(expected, result) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestChangeSustainAt(t *testing.T) {
+	notes := []Note{{0, 0, 0}, {192, 0, 48}, {192, 2, 48}}
+
+	result := changeSustainAt(notes, 192, 48)
+	expected := []Note{{0, 0, 0}, {192, 0, 96}, {192, 2, 96}}
+	if !reflect.DeepEqual(expected, result) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+
+	result = changeSustainAt(result, 192, -200)
+	expected = []Note{{0, 0, 0}, {192, 0, 0}, {192, 2, 0}}
+	if !reflect.DeepEqual(expected, result) {
+		t.Errorf("Expected sustain to not go below 0, got %v", result)
+	}
+}
+
+func TestSetSyncTrackElement(t *testing.T) {
+	syncTrack := []SyncTrackElement{{0, "B", 120000}, {0, "TS", 4}, {768, "B", 140000}}
+
+	syncTrack = setSyncTrackElement(syncTrack, 384, "B", 100000)
+	syncTrack = setSyncTrackElement(syncTrack, 768, "B", 150000)
+	syncTrack = setSyncTrackElement(syncTrack, 768, "TS", 3)
+
+	expected := []SyncTrackElement{{0, "B", 120000}, {0, "TS", 4}, {384, "B", 100000}, {768, "B", 150000}, {768, "TS", 3}}
+	if !reflect.DeepEqual(expected, syncTrack) {
+		t.Errorf("Expected %v, got %v", expected, syncTrack)
+	}
+
+	syncTrack = removeSyncTrackElement(syncTrack, 384, "B")
+	expected = []SyncTrackElement{{0, "B", 120000}, {0, "TS", 4}, {768, "B", 150000}, {768, "TS", 3}}
+	if !reflect.DeepEqual(expected, syncTrack) {
+		t.Errorf("Expected %v, got %v", expected, syncTrack)
+	}
+}
+
+func TestBeatPosition_WithTimeSignatureChange(t *testing.T) {
+	syncTrack := []SyncTrackElement{{0, "B", 120000}, {0, "TS", 4}, {1536, "TS", 3}}
+
+	testCases := []struct {
+		tick                  int
+		measure, beat, offset int
+	}{
+		{0, 0, 0, 0},
+		{96, 0, 0, 96},
+		{192 * 5, 1, 1, 0},
+		{1536, 2, 0, 0},
+		{1536 + 192*3, 3, 0, 0},
+		{1536 + 192*4 + 10, 3, 1, 10},
+	}
+
+	for _, tc := range testCases {
+		measure, beat, offset := beatPosition(syncTrack, 192, tc.tick)
+		if measure != tc.measure || beat != tc.beat || offset != tc.offset {
+			t.Errorf("Expected tick %d to be measure %d beat %d offset %d, got %d %d %d",
+				tc.tick, tc.measure, tc.beat, tc.offset, measure, beat, offset)
+		}
+	}
+}
+
+func createTestEditorModel(t *testing.T) chartEditorModel {
+	chart := openCultOfPersonalityChart(t)
+	return chartEditorModel{
+		chart: chart,
+		chartInfo: chartInfo{
+			fullFolderPath: t.TempDir(),
+			track:          parseTrackName("ExpertSingle"),
+		},
+		settings:  defaultSettings(),
+		gridIndex: defaultEditorGridIndex,
+	}
+}
+
+func sendEditorKeys(m chartEditorModel, keys ...string) chartEditorModel {
+	for _, k := range keys {
+		var msg tea.KeyMsg
+		switch k {
+		case "up":
+			msg = tea.KeyMsg{Type: tea.KeyUp}
+		case "down":
+			msg = tea.KeyMsg{Type: tea.KeyDown}
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		case "ctrl+s":
+			msg = tea.KeyMsg{Type: tea.KeyCtrlS}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		}
+		model, _ := m.Update(msg)
+		m = model.(chartEditorModel)
+	}
+	return m
+}
+
+func TestEditor_PlacesChordWithSustain(t *testing.T) {
+	m := createTestEditorModel(t)
+
+	// 16th note grid at 192 resolution
+	m = sendEditorKeys(m, "up", "up", "1", "3", "+", "+")
+
+	if m.cursorTick != 96 {
+		t.Fatal("Expected cursor at tick 96, got", m.cursorTick)
+	}
+
+	chord := []Note{}
+	for _, note := range m.trackNotes() {
+		if note.TimeStamp == 96 {
+			chord = append(chord, note)
+		}
+	}
+
+	expected := []Note{{96, 0, 96}, {96, 2, 96}}
+	if !reflect.DeepEqual(expected, chord) {
+		t.Errorf("Expected %v, got %v", expected, chord)
+	}
+
+	if !m.dirty {
+		t.Error("Expected editor to have unsaved changes")
+	}
+}
+
+func TestEditor_SetsBpmAtCursor(t *testing.T) {
+	m := createTestEditorModel(t)
+	m = sendEditorKeys(m, "]", "]", "]", "]", "]", "]", "up")
+	m = sendEditorKeys(m, "b", "1", "5", "0", ".", "5", "enter")
+
+	if m.prompt != nil {
+		t.Fatal("Expected prompt to be closed")
+	}
+
+	found := false
+	for _, sync := range m.chart.SyncTrack {
+		if sync.TimeStamp == m.cursorTick && sync.Type == "B" {
+			found = sync.Value == 150500
+		}
+	}
+	if !found {
+		t.Error("Expected BPM of 150500 at tick", m.cursorTick)
+	}
+}
+
+func TestEditor_SavedChartCanBeParsed(t *testing.T) {
+	m := createTestEditorModel(t)
+	m = sendEditorKeys(m, "5", "ctrl+s")
+
+	if m.dirty {
+		t.Fatal("Expected no unsaved changes after saving, got status", m.statusMsg)
+	}
+
+	parsed := openSampleChart(filepath.Join(m.chartInfo.fullFolderPath, "notes.chart"), t)
+
+	if !reflect.DeepEqual(parsed.Tracks["ExpertSingle"], m.chart.Tracks["ExpertSingle"]) {
+		t.Error("Expected written ExpertSingle track to match the edited track")
+	}
+	if !reflect.DeepEqual(parsed.SyncTrack, m.chart.SyncTrack) {
+		t.Error("Expected written SyncTrack to match")
+	}
+	if parsed.Tracks["ExpertSingle"][0] != (Note{0, 4, 0}) {
+		t.Error("Expected the new note to be first, got", parsed.Tracks["ExpertSingle"][0])
+	}
+}
+
+func TestEditorCells_SixFret(t *testing.T) {
+	m := chartEditorModel{
+		chart:     parseTestChart(t, sixFretChart),
+		chartInfo: chartInfo{track: parseTrackName("ExpertGHLGuitar")},
+		settings:  defaultSettings(),
+		gridIndex: defaultEditorGridIndex,
+	}
+
+	laneCells, _ := m.createEditorCells()
+	if len(laneCells[0]) != 6 {
+		t.Fatal("Expected 6 lanes, got", len(laneCells[0]))
+	}
+	lanesWithNotes := func(tick int) []int {
+		lanes := []int{}
+		for lane, cell := range laneCells[m.tickRow(tick)] {
+			if cell.note {
+				lanes = append(lanes, lane)
+			}
+		}
+		return lanes
+	}
+	// black 1 and white 1, then black 3, which is raw note 8
+	if lanes := lanesWithNotes(0); !reflect.DeepEqual(lanes, []int{0, 1}) {
+		t.Error("Expected the chord in lanes 0 and 1, got", lanes)
+	}
+	if lanes := lanesWithNotes(192); !reflect.DeepEqual(lanes, []int{4}) {
+		t.Error("Expected black 3 in lane 4, got", lanes)
+	}
+}
+
+func TestEditor_SetsTimeSignatureWithDenominator(t *testing.T) {
+	m := createTestEditorModel(t)
+	m = sendEditorKeys(m, "pgup", "t", "7", "/", "8", "enter")
+
+	tsTick, numerator := timeSignatureAt(m.chart.SyncTrack, m.cursorTick)
+	if tsTick != m.cursorTick || numerator != 7 {
+		t.Fatal("Expected a 7 beat time signature at tick", m.cursorTick)
+	}
+	if m.chart.TimeSignatureDenominators[m.cursorTick] != 3 {
+		t.Error("Expected an eighth note denominator, got", m.chart.TimeSignatureDenominators[m.cursorTick])
+	}
+
+	m = sendEditorKeys(m, "ctrl+s")
+	parsed := openSampleChart(filepath.Join(m.chartInfo.fullFolderPath, "notes.chart"), t)
+	if parsed.TimeSignatureDenominators[m.cursorTick] != 3 {
+		t.Error("Expected the denominator to be saved, got", parsed.TimeSignatureDenominators)
+	}
+
+	// removing the time signature removes its denominator too
+	m = sendEditorKeys(m, "t", "enter")
+	if _, ok := m.chart.TimeSignatureDenominators[m.cursorTick]; ok {
+		t.Error("Expected the denominator to be removed")
+	}
+}
+
+func TestParseTimeSignature(t *testing.T) {
+	testCases := []struct {
+		value                  string
+		numerator, denominator int
+		ok                     bool
+	}{
+		{"3", 3, 2, true},
+		{"6/8", 6, 3, true},
+		{"5 / 16", 5, 4, true},
+		{"7/6", 0, 0, false},
+		{"/4", 0, 0, false},
+		{"0/4", 0, 0, false},
+	}
+	for _, tc := range testCases {
+		numerator, denominator, ok := parseTimeSignature(tc.value)
+		if numerator != tc.numerator || denominator != tc.denominator || ok != tc.ok {
+			t.Errorf("Expected %q to parse to %d %d %t, got %d %d %t",
+				tc.value, tc.numerator, tc.denominator, tc.ok, numerator, denominator, ok)
+		}
+	}
+}
+
+func TestEditor_SixFretKeysPlaceFrets(t *testing.T) {
+	m := chartEditorModel{
+		chart:     parseTestChart(t, sixFretChart),
+		chartInfo: chartInfo{fullFolderPath: t.TempDir(), track: parseTrackName("ExpertGHLGuitar")},
+		settings:  defaultSettings(),
+		gridIndex: defaultEditorGridIndex,
+	}
+	m = sendEditorKeys(m, "up", "w", "3")
+
+	if m.cursorTick != 48 {
+		t.Fatal("Expected w to place a note instead of moving the cursor, got tick", m.cursorTick)
+	}
+	chord := []Note{}
+	for _, note := range m.trackNotes() {
+		if note.TimeStamp == 48 {
+			chord = append(chord, note)
+		}
+	}
+	// white 2 and black 3
+	expected := []Note{{48, 1, 0}, {48, 8, 0}}
+	if !reflect.DeepEqual(expected, chord) {
+		t.Errorf("Expected %v, got %v", expected, chord)
+	}
+}
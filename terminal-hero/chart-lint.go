@@ -0,0 +1,325 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type lintSeverity int
+
+const (
+	lintInfo lintSeverity = iota
+	lintWarning
+	lintError
+)
+
+func (s lintSeverity) String() string {
+	switch s {
+	case lintError:
+		return "error"
+	case lintWarning:
+		return "warning"
+	}
+	return "info"
+}
+
+// names of the checks run by lintChart
+const (
+	lintCheckSectionFormat = "section-format"
+	lintCheckElementFormat = "element-format"
+	lintCheckResolution    = "resolution"
+	lintCheckSyncFormat    = "sync-track-format"
+	lintCheckSyncOrder     = "sync-track-order"
+	lintCheckTempo         = "tempo"
+	lintCheckNoteFormat    = "note-format"
+	lintCheckNoteOrder     = "note-order"
+	lintCheckDuplicateNote = "duplicate-note"
+	lintCheckEmptyTrack    = "empty-track"
+	lintCheckNoNotes       = "no-notes"
+)
+
+type lintProblem struct {
+	check    string
+	severity lintSeverity
+	line     int // 1 based, 0 when the problem isn't about a single line
+	message  string
+}
+
+func (p lintProblem) String() string {
+	location := "line " + strconv.Itoa(p.line)
+	if p.line == 0 {
+		location = "chart"
+	}
+	return fmt.Sprintf("%s: %s [%s] %s", location, p.severity, p.check, p.message)
+}
+
+type chartLinter struct {
+	problems []lintProblem
+
+	section        string
+	sectionLine    int
+	inSection      bool
+	resolutionSeen bool
+	lastTick       int
+	lastNoteKeys   map[string]bool
+	sectionNotes   int
+	tracksWithNote int
+	tempoAtZero    bool
+}
+
+func (l *chartLinter) report(check string, severity lintSeverity, line int, format string, a ...any) {
+	l.problems = append(l.problems, lintProblem{check, severity, line, fmt.Sprintf(format, a...)})
+}
+
+func isTrackSection(section string) bool {
+	return section != "Song" && section != "SyncTrack" && section != "Events"
+}
+
+// checks the chart for problems that would make it fail to parse or play wrong.
+// every line is checked so that all of the problems are reported, not just the first
+func lintChart(reader io.Reader) ([]lintProblem, error) {
+	l := &chartLinter{}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		l.handleChartLine(lineNumber, tokenizeChartLine(scanner.Text()))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return l.finish(), nil
+}
+
+// the problems with the whole chart, once every line has been checked
+func (l *chartLinter) finish() []lintProblem {
+	if l.inSection {
+		l.report(lintCheckSectionFormat, lintError, l.sectionLine, "section [%s] is never closed", l.section)
+		l.endSection()
+	}
+	if !l.resolutionSeen {
+		l.report(lintCheckResolution, lintWarning, 0, "the [Song] section has no Resolution, 192 will be used")
+	}
+	if !l.tempoAtZero {
+		l.report(lintCheckTempo, lintWarning, 0, "there is no BPM at tick 0, 120 BPM will be used")
+	}
+	if l.tracksWithNote == 0 {
+		l.report(lintCheckNoNotes, lintWarning, 0, "no track has any notes")
+	}
+	return l.problems
+}
+
+func (l *chartLinter) handleChartLine(lineNumber int, line chartLine) {
+	switch line.kind {
+	case chartLineBlank:
+		return
+	case chartLineSection:
+		if l.inSection {
+			l.report(lintCheckSectionFormat, lintError, lineNumber, "section [%s] is never closed", l.section)
+			l.endSection()
+		}
+		l.section = line.section
+		l.sectionLine = lineNumber
+		l.lastTick = 0
+		l.lastNoteKeys = make(map[string]bool)
+		l.sectionNotes = 0
+	case chartLineOpen:
+		l.inSection = true
+	case chartLineClose:
+		if !l.inSection {
+			l.report(lintCheckSectionFormat, lintError, lineNumber, "} without a matching {")
+			return
+		}
+		l.endSection()
+	case chartLineInvalid:
+		if strings.HasPrefix(line.text, "[") {
+			l.report(lintCheckSectionFormat, lintError, lineNumber, "section header %q is missing a ]", line.text)
+		} else {
+			l.report(lintCheckElementFormat, lintError, lineNumber, "%q is not a key = value pair", line.text)
+		}
+	case chartLineElement:
+		if !l.inSection {
+			l.report(lintCheckSectionFormat, lintError, lineNumber, "%q is outside of a section", line.text)
+			return
+		}
+		l.lintElement(lineNumber, line.element.LeftValue, line.element.RightValue)
+	}
+}
+
+func (l *chartLinter) endSection() {
+	l.inSection = false
+	if !isTrackSection(l.section) {
+		return
+	}
+	if l.sectionNotes == 0 {
+		l.report(lintCheckEmptyTrack, lintInfo, l.sectionLine, "track [%s] has no notes", l.section)
+	} else {
+		l.tracksWithNote++
+	}
+}
+
+func (l *chartLinter) lintElement(lineNumber int, left string, right string) {
+	if l.section == "Song" {
+		if left == "Resolution" {
+			l.resolutionSeen = true
+			resolution, err := strconv.Atoi(right)
+			if err != nil || resolution <= 0 {
+				l.report(lintCheckResolution, lintWarning, lineNumber, "resolution %q must be a number above 0, 192 will be used", right)
+			}
+		}
+		return
+	}
+
+	tick, err := strconv.Atoi(left)
+	if err != nil || tick < 0 {
+		l.report(lintCheckElementFormat, lintError, lineNumber, "tick %q is not a positive number", left)
+		return
+	}
+
+	orderCheck := lintCheckNoteOrder
+	if l.section == "SyncTrack" {
+		orderCheck = lintCheckSyncOrder
+	}
+	if tick < l.lastTick {
+		// the parser sorts them, so they still play in the right order
+		l.report(orderCheck, lintWarning, lineNumber, "tick %d is before the previous tick %d", tick, l.lastTick)
+	}
+	if tick != l.lastTick {
+		l.lastNoteKeys = make(map[string]bool)
+	}
+	l.lastTick = tick
+
+	fields := strings.Fields(right)
+	if l.section == "SyncTrack" {
+		l.lintSyncTrackElement(lineNumber, tick, fields)
+	} else if isTrackSection(l.section) && len(fields) > 0 && fields[0] == "N" {
+		l.lintNote(lineNumber, fields)
+	}
+}
+
+func (l *chartLinter) lintSyncTrackElement(lineNumber int, tick int, fields []string) {
+	if len(fields) < 2 {
+		l.report(lintCheckSyncFormat, lintError, lineNumber, "expected a type and a value, got %q", strings.Join(fields, " "))
+		return
+	}
+	value, err := strconv.Atoi(fields[1])
+	if err != nil {
+		l.report(lintCheckSyncFormat, lintError, lineNumber, "value %q is not a number", fields[1])
+		return
+	}
+	if fields[0] == "TS" && len(fields) > 2 {
+		if _, err := strconv.Atoi(fields[2]); err != nil {
+			l.report(lintCheckSyncFormat, lintError, lineNumber, "time signature denominator %q is not a number", fields[2])
+		}
+	}
+	if fields[0] == "B" {
+		if value <= 0 {
+			l.report(lintCheckTempo, lintError, lineNumber, "BPM must be above 0, got %s", formatBpm(value))
+		}
+		if tick == 0 {
+			l.tempoAtZero = true
+		}
+	}
+	if fields[0] == "TS" && value <= 0 {
+		l.report(lintCheckSyncFormat, lintError, lineNumber, "time signature must be above 0, got %d", value)
+	}
+}
+
+func (l *chartLinter) lintNote(lineNumber int, fields []string) {
+	if len(fields) < 3 {
+		l.report(lintCheckNoteFormat, lintError, lineNumber, "expected N <type> <length>, got %q", strings.Join(fields, " "))
+		return
+	}
+	_, typeErr := strconv.Atoi(fields[1])
+	length, lengthErr := strconv.ParseInt(fields[2], 10, 64)
+	if typeErr != nil || lengthErr != nil || length < 0 {
+		l.report(lintCheckNoteFormat, lintError, lineNumber, "expected N <type> <length>, got %q", strings.Join(fields, " "))
+		return
+	}
+
+	l.sectionNotes++
+	if l.lastNoteKeys[fields[1]] {
+		l.report(lintCheckDuplicateNote, lintWarning, lineNumber, "note %s is repeated at the same tick", fields[1])
+	}
+	l.lastNoteKeys[fields[1]] = true
+}
+
+func hasLintErrors(problems []lintProblem) bool {
+	for _, p := range problems {
+		if p.severity == lintError {
+			return true
+		}
+	}
+	return false
+}
+
+// the problems at or above the severity
+func filterLintProblems(problems []lintProblem, minSeverity lintSeverity) []lintProblem {
+	result := make([]lintProblem, 0)
+	for _, p := range problems {
+		if p.severity >= minSeverity {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+func lintChartFile(chartFilePath string) ([]lintProblem, error) {
+	file, err := os.Open(chartFilePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return lintChart(file)
+}
+
+// parses the chart and lints it from the same lines, so the chart is only read once.
+// only a parse error stops the chart from loading, the problems are just reported
+func parseAndLintChart(reader io.Reader) (*Chart, []lintProblem, error) {
+	l := &chartLinter{}
+	chart, err := parseWithLineHandler(reader, l)
+	if err != nil {
+		return nil, nil, err
+	}
+	return chart, l.finish(), nil
+}
+
+// runs the lint command with the arguments after "lint" and returns the exit code
+func runLintCommand(args []string, out io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprintln(out, "usage: terminal-hero lint <notes.chart or song folder>...")
+		return 2
+	}
+
+	exitCode := 0
+	for _, path := range args {
+		chartFilePath := path
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			chartFilePath = filepath.Join(path, "notes.chart")
+		}
+
+		problems, err := lintChartFile(chartFilePath)
+		if err != nil {
+			fmt.Fprintf(out, "%s: %v\n", chartFilePath, err)
+			exitCode = 1
+			continue
+		}
+
+		for _, p := range problems {
+			fmt.Fprintf(out, "%s: %s\n", chartFilePath, p)
+		}
+		if hasLintErrors(problems) {
+			exitCode = 1
+		} else if len(filterLintProblems(problems, lintWarning)) == 0 {
+			fmt.Fprintf(out, "%s: ok\n", chartFilePath)
+		}
+	}
+	return exitCode
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLintChart_SampleSongsHaveNoWarnings(t *testing.T) {
+	chartPaths, err := filepath.Glob("sample-songs/*.chart")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, chartPath := range chartPaths {
+		problems, err := lintChartFile(chartPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		warnings := filterLintProblems(problems, lintWarning)
+		if len(warnings) != 0 {
+			t.Errorf("Expected no warnings for %s, got %v", chartPath, warnings)
+		}
+	}
+}
+
+const brokenChart = `[Song]
+{
+	Name = Broken
+	Resolution = 0
+}
+[SyncTrack]
+{
+	0 = B
+	0 = TS 4
+	768 = B 0
+	384 = B 120000
+}
+[ExpertSingle]
+{
+	0 = N 0 0
+	192 = N 1 0
+	192 = N 1 0
+	96 = N 2 0
+	384 = N 3
+}
+[HardSingle]
+{
+}
+`
+
+func TestLintChart_ReportsNamedChecksWithLineNumbers(t *testing.T) {
+	problems, err := lintChart(strings.NewReader(brokenChart))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []lintProblem{
+		{lintCheckResolution, lintWarning, 4, ""},
+		{lintCheckSyncFormat, lintError, 8, ""},
+		{lintCheckTempo, lintError, 10, ""},
+		{lintCheckSyncOrder, lintWarning, 11, ""},
+		{lintCheckDuplicateNote, lintWarning, 17, ""},
+		{lintCheckNoteOrder, lintWarning, 18, ""},
+		{lintCheckNoteFormat, lintError, 19, ""},
+		{lintCheckEmptyTrack, lintInfo, 21, ""},
+		{lintCheckTempo, lintWarning, 0, ""},
+	}
+
+	if len(problems) != len(expected) {
+		t.Fatalf("Expected %d problems, got %d: %v", len(expected), len(problems), problems)
+	}
+	for i, p := range problems {
+		if p.check != expected[i].check || p.severity != expected[i].severity || p.line != expected[i].line {
+			t.Errorf("Expected problem %d to be %s %s on line %d, got %v",
+				i, expected[i].severity, expected[i].check, expected[i].line, p)
+		}
+	}
+
+	if !hasLintErrors(problems) {
+		t.Error("Expected the chart to have errors")
+	}
+}
+
+func TestLintChart_UnclosedSectionAndNoNotes(t *testing.T) {
+	chartText := "[Song]\n{\n\tResolution = 192\n}\n[SyncTrack]\n{\n\t0 = B 120000\n[ExpertSingle]\n{\n}\n"
+
+	problems, err := lintChart(strings.NewReader(chartText))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checks := []string{}
+	for _, p := range filterLintProblems(problems, lintWarning) {
+		checks = append(checks, p.check+":"+p.String())
+	}
+
+	if len(checks) != 2 ||
+		!strings.HasPrefix(checks[0], lintCheckSectionFormat+":line 8:") ||
+		!strings.HasPrefix(checks[1], lintCheckNoNotes+":chart:") {
+		t.Error("Expected an unclosed section on line 8 and no notes, got", checks)
+	}
+}
+
+func TestRunLintCommand(t *testing.T) {
+	out := strings.Builder{}
+	code := runLintCommand([]string{"sample-songs/cult-of-personality.chart"}, &out)
+	if code != 0 {
+		t.Error("Expected exit code 0, got", code, out.String())
+	}
+	if !strings.HasSuffix(out.String(), "cult-of-personality.chart: ok\n") {
+		t.Error("Expected the chart to be ok, got", out.String())
+	}
+
+	out.Reset()
+	code = runLintCommand([]string{"sample-songs/missing.chart"}, &out)
+	if code != 1 {
+		t.Error("Expected exit code 1 for a missing chart, got", code)
+	}
+}
+
+func TestInitializeChart_LoadsChartsWithLintErrors(t *testing.T) {
+	folderPath := t.TempDir()
+	// no Resolution, notes out of order and a BPM of 0
+	chartText := "[Song]\n{\n\tName = Test\n}\n[SyncTrack]\n{\n\t0 = B 120000\n\t384 = B 0\n}\n" +
+		"[ExpertSingle]\n{\n\t192 = N 1 0\n\t0 = N 2 0\n}\n"
+	writeTestSongFolder(t, folderPath, chartText)
+
+	chart, _, lintProblems, err := initializeChart(folderPath)
+	if err != nil {
+		t.Fatal("Expected the chart to load, got", err)
+	}
+	if chart.Tracks["ExpertSingle"][0].TimeStamp != 0 {
+		t.Error("Expected the notes to be sorted, got", chart.Tracks["ExpertSingle"])
+	}
+	if !hasLintErrors(lintProblems) {
+		t.Error("Expected the lint errors to be passed on to be shown, got", lintProblems)
+	}
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// WriteChart writes the chart to the writer in canonical .chart format.
+// sections are written in the order they were parsed in, and elements the
+// parser doesn't understand are written back alongside the known ones
+func WriteChart(writer io.Writer, chart *Chart) error {
+	w := bufio.NewWriter(writer)
+
+	for _, section := range chartSectionOrder(chart) {
+		writeSectionStart(w, section)
+		switch section {
+		case "Song":
+			writeSongSection(w, chart)
+		case "SyncTrack":
+			writeSyncTrackSection(w, chart)
+		case "Events":
+			writeEventsSection(w, chart)
+		default:
+			writeTrackSection(w, chart.Tracks[section], chart.TrackEvents[section], chart.UnknownElements[section])
+		}
+		writeSectionEnd(w)
+	}
+
+	return w.Flush()
+}
+
+// the sections in the order they were parsed in. the Song, SyncTrack and Events
+// sections are always included, and sections that were added after parsing
+// are written at the end
+func chartSectionOrder(chart *Chart) []string {
+	sections := make([]string, 0, len(chart.Sections)+3)
+	seen := make(map[string]bool)
+	add := func(section string) {
+		if !seen[section] {
+			seen[section] = true
+			sections = append(sections, section)
+		}
+	}
+
+	for _, section := range []string{"Song", "SyncTrack", "Events"} {
+		if !containsString(chart.Sections, section) {
+			add(section)
+		}
+	}
+	for _, section := range chart.Sections {
+		add(section)
+	}
+
+	trackNames := make([]string, 0, len(chart.Tracks))
+	for k := range chart.Tracks {
+		if !seen[k] {
+			trackNames = append(trackNames, k)
+		}
+	}
+	for _, track := range sortTracks(trackNames) {
+		add(track.fullTrackName)
+	}
+
+	otherSections := make([]string, 0)
+	for k := range chart.UnknownElements {
+		if !seen[k] {
+			otherSections = append(otherSections, k)
+		}
+	}
+	for k := range chart.TrackEvents {
+		if !seen[k] && !containsString(otherSections, k) {
+			otherSections = append(otherSections, k)
+		}
+	}
+	sort.Strings(otherSections)
+	for _, section := range otherSections {
+		add(section)
+	}
+
+	return sections
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// the order that known [Song] fields are written in when they weren't in the parsed chart
+var songFieldOrder = []string{
+	"Name", "Artist", "Charter", "Album", "Year", "Offset", "Resolution", "Player2", "Difficulty",
+	"PreviewStart", "PreviewEnd", "Genre", "MediaType", "MusicStream", "GuitarStream", "RhythmStream",
+	"BassStream", "DrumStream", "KeysStream", "VocalStream", "CrowdStream",
+}
+
+func writeSongSection(w *bufio.Writer, chart *Chart) {
+	metadata := &chart.SongMetadata
+
+	written := make(map[string]bool)
+	for _, field := range metadata.Fields {
+		writeChartElement(w, field.LeftValue, songFieldRawValue(metadata, field))
+		written[field.LeftValue] = true
+	}
+
+	for _, key := range songFieldOrder {
+		if written[key] {
+			continue
+		}
+		value, _ := metadata.fieldValue(key)
+		required := key == "Name" || key == "Offset" || key == "Resolution"
+		if required || (value != "" && value != "0") {
+			writeChartElement(w, key, value)
+		}
+	}
+}
+
+// the parsed value unless the field was changed after parsing, in which case
+// the new value is written with the same quoting as the original
+func songFieldRawValue(metadata *SongMetadata, field ChartElement) string {
+	value, known := metadata.fieldValue(field.LeftValue)
+	if !known {
+		return field.RightValue
+	}
+
+	parsed := SongMetadata{}
+	parsed.setField(field.LeftValue, field.RightValue)
+	original, _ := parsed.fieldValue(field.LeftValue)
+	if value == original {
+		return field.RightValue
+	}
+
+	if field.LeftValue == "Year" && strings.HasPrefix(unquoteChartValue(field.RightValue), ", ") {
+		value = ", " + value
+	}
+	if strings.HasPrefix(field.RightValue, "\"") {
+		value = "\"" + value + "\""
+	}
+	return value
+}
+
+func writeSyncTrackSection(w *bufio.Writer, chart *Chart) {
+	unknown := chart.UnknownElements["SyncTrack"]
+	for _, sync := range chart.SyncTrack {
+		unknown = writeUnknownElementsBefore(w, unknown, sync.TimeStamp)
+
+		value := fmt.Sprintf("%s %d", sync.Type, sync.Value)
+		denominator, ok := chart.TimeSignatureDenominators[sync.TimeStamp]
+		if sync.Type == "TS" && ok {
+			value += " " + strconv.Itoa(denominator)
+		}
+		writeChartElement(w, strconv.Itoa(sync.TimeStamp), value)
+	}
+	writeUnknownElementsBefore(w, unknown, math.MaxInt)
+}
+
+func writeEventsSection(w *bufio.Writer, chart *Chart) {
+	unknown := chart.UnknownElements["Events"]
+	for _, event := range chart.Events {
+		unknown = writeUnknownElementsBefore(w, unknown, event.TimeStamp)
+		writeChartEvent(w, event)
+	}
+	writeUnknownElementsBefore(w, unknown, math.MaxInt)
+}
+
+// notes are written before events at the same tick
+func writeTrackSection(w *bufio.Writer, notes []Note, events []ChartEvent, unknown []ChartElement) {
+	for _, note := range notes {
+		for len(events) > 0 && events[0].TimeStamp < note.TimeStamp {
+			unknown = writeUnknownElementsBefore(w, unknown, events[0].TimeStamp)
+			writeChartEvent(w, events[0])
+			events = events[1:]
+		}
+		unknown = writeUnknownElementsBefore(w, unknown, note.TimeStamp)
+		writeChartElement(w, strconv.Itoa(note.TimeStamp), fmt.Sprintf("N %d %d", note.RawNoteType, note.ExtraData))
+	}
+	for _, event := range events {
+		unknown = writeUnknownElementsBefore(w, unknown, event.TimeStamp)
+		writeChartEvent(w, event)
+	}
+	writeUnknownElementsBefore(w, unknown, math.MaxInt)
+}
+
+func writeChartEvent(w *bufio.Writer, event ChartEvent) {
+	value := event.Type
+	if event.Value != "" {
+		value += " " + event.Value
+	}
+	writeChartElement(w, strconv.Itoa(event.TimeStamp), value)
+}
+
+// writes the elements that are before the tick and returns the rest.
+// elements without a tick are written straight away
+func writeUnknownElementsBefore(w *bufio.Writer, elements []ChartElement, tick int) []ChartElement {
+	for len(elements) > 0 {
+		elementTick, err := strconv.Atoi(elements[0].LeftValue)
+		if err == nil && elementTick >= tick {
+			break
+		}
+		writeChartElement(w, elements[0].LeftValue, elements[0].RightValue)
+		elements = elements[1:]
+	}
+	return elements
+}
+
+func writeSectionStart(w *bufio.Writer, section string) {
+	w.WriteString("[" + section + "]\n{\n")
+}
+
+func writeSectionEnd(w *bufio.Writer) {
+	w.WriteString("}\n")
+}
+
+func writeChartElement(w *bufio.Writer, left string, right string) {
+	w.WriteString("\t" + left + " = " + right + "\n")
+}
+
+// writes the chart to the file path. the previous chart file is kept
+// as a .bak file the first time the chart is overwritten. the chart is written to a
+// temporary file first, so a failed write doesn't lose the chart that's already there
+func saveChartFile(chartFilePath string, chart *Chart) error {
+	file, err := os.CreateTemp(filepath.Dir(chartFilePath), filepath.Base(chartFilePath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tempFilePath := file.Name()
+	err = writeChartFile(file, chartFilePath, chart)
+	if err != nil {
+		os.Remove(tempFilePath)
+		return err
+	}
+
+	backupFilePath := chartFilePath + ".bak"
+	if fileExists(chartFilePath) && !fileExists(backupFilePath) {
+		err = os.Rename(chartFilePath, backupFilePath)
+		if err != nil {
+			os.Remove(tempFilePath)
+			return err
+		}
+	}
+	return os.Rename(tempFilePath, chartFilePath)
+}
+
+// writes the chart to the temporary file and closes it. the file gets the
+// permissions of the chart that it replaces
+func writeChartFile(file *os.File, chartFilePath string, chart *Chart) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(chartFilePath); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	err := file.Chmod(mode)
+	if err == nil {
+		err = WriteChart(file, chart)
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	if err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
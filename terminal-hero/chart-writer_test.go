@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriteChart_RoundTripsSampleSongs(t *testing.T) {
+	chartPaths, err := filepath.Glob("sample-songs/*.chart")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chartPaths) == 0 {
+		t.Fatal("Expected sample charts")
+	}
+
+	for _, chartPath := range chartPaths {
+		original, err := os.ReadFile(chartPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		chart := openSampleChart(chartPath, t)
+
+		var written bytes.Buffer
+		err = WriteChart(&written, chart)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(original, written.Bytes()) {
+			t.Errorf("Expected %s to round trip byte for byte", chartPath)
+		}
+	}
+}
+
+const chartWithUnknownElements = `[Song]
+{
+	Name = "Test Song"
+	Artist = "Test Artist"
+	Charter = "Someone"
+	Offset = 0
+	Resolution = 192
+	Player2 = bass
+	MusicStream = "song.ogg"
+}
+[SyncTrack]
+{
+	0 = TS 6 3
+	0 = B 120000
+	768 = A 500000
+	768 = B 140000
+}
+[Events]
+{
+	0 = E "section Intro"
+	768 = E "section Verse"
+}
+[ExpertSingle]
+{
+	0 = N 0 0
+	0 = S 2 384
+	192 = N 1 96
+	192 = E solo
+	384 = N 7 0
+}
+[ExpertDoubleBass]
+{
+}
+`
+
+func TestWriteChart_KeepsUnknownElements(t *testing.T) {
+	chart, err := ParseF(strings.NewReader(chartWithUnknownElements))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var written bytes.Buffer
+	err = WriteChart(&written, chart)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if written.String() != chartWithUnknownElements {
+		t.Errorf("Expected chart to round trip, got\n%s", written.String())
+	}
+
+	reparsed, err := ParseF(&written)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(chart, reparsed) {
+		t.Error("Expected the written chart to parse to the same chart")
+	}
+}
+
+func TestWriteChart_WritesEditedValues(t *testing.T) {
+	chart, err := ParseF(strings.NewReader(chartWithUnknownElements))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chart.SongMetadata.Offset = 250
+	chart.SongMetadata.Artist = "New Artist"
+	chart.SongMetadata.Album = "New Album"
+	chart.Tracks["ExpertSingle"] = toggleNote(chart.Tracks["ExpertSingle"], 96, 4)
+	chart.Tracks["HardSingle"] = []Note{{0, 1, 0}}
+
+	var written bytes.Buffer
+	err = WriteChart(&written, chart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := written.String()
+
+	expectedLines := []string{
+		"\tOffset = 250\n",
+		"\tArtist = \"New Artist\"\n",
+		"\tMusicStream = \"song.ogg\"\n\tAlbum = New Album\n}\n",
+		"\t0 = N 0 0\n\t0 = S 2 384\n\t96 = N 4 0\n\t192 = N 1 96\n\t192 = E solo\n",
+		"[ExpertDoubleBass]\n{\n}\n[HardSingle]\n{\n\t0 = N 1 0\n}\n",
+	}
+	for _, expected := range expectedLines {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected written chart to contain %q, got\n%s", expected, text)
+		}
+	}
+}
+
+func TestWriteChart_NewChartHasRequiredSections(t *testing.T) {
+	chart := &Chart{
+		SongMetadata: SongMetadata{Name: "New", Resolution: 192},
+		SyncTrack:    []SyncTrackElement{{0, "B", 120000}},
+		Tracks:       map[string][]Note{"ExpertSingle": {{0, 0, 0}}},
+	}
+
+	var written bytes.Buffer
+	err := WriteChart(&written, chart)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "[Song]\n{\n\tName = New\n\tOffset = 0\n\tResolution = 192\n}\n" +
+		"[SyncTrack]\n{\n\t0 = B 120000\n}\n" +
+		"[Events]\n{\n}\n" +
+		"[ExpertSingle]\n{\n\t0 = N 0 0\n}\n"
+	if written.String() != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, written.String())
+	}
+}
+
+func TestSaveChartFile_KeepsTheFirstBackup(t *testing.T) {
+	folderPath := t.TempDir()
+	chartFilePath := filepath.Join(folderPath, "notes.chart")
+	original := "[Song]\n{\n\tResolution = 192\n}\n"
+	err := os.WriteFile(chartFilePath, []byte(original), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chart := openCultOfPersonalityChart(t)
+	for i := 0; i < 2; i++ {
+		err = saveChartFile(chartFilePath, chart)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	backup, err := os.ReadFile(chartFilePath + ".bak")
+	if err != nil || string(backup) != original {
+		t.Error("Expected the backup to be the original chart, got", string(backup), err)
+	}
+	saved := openSampleChart(chartFilePath, t)
+	if !reflect.DeepEqual(saved.Tracks, chart.Tracks) {
+		t.Error("Expected the saved chart to have the chart's tracks")
+	}
+	files, _ := os.ReadDir(folderPath)
+	if len(files) != 2 {
+		t.Error("Expected only the chart and its backup, got", files)
+	}
+}
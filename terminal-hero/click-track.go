@@ -0,0 +1,208 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/faiface/beep"
+)
+
+const (
+	clickDuration       = 30 * time.Millisecond
+	clickFrequency      = 1000.0 // Hz
+	accentFrequency     = 1500.0 // Hz, for the first beat of a measure
+	hitSoundDuration    = 15 * time.Millisecond
+	hitSoundFrequency   = 2500.0 // Hz
+	clickDecayPerSecond = 150.0  // how fast the clicks fade out
+
+	defaultClickVolume = 0.5
+
+	// used when the chart has no beat lines to count in with
+	defaultCountInBeatMs = 500
+	defaultCountInBeats  = 4
+)
+
+// a click sound that can be turned on and off, with its own volume
+type clickSettings struct {
+	enabled bool
+	volume  float64 // 0 to 1
+}
+
+// a short sine wave that fades out
+func synthesizeClick(format beep.Format, frequency float64, duration time.Duration) [][2]float64 {
+	samples := make([][2]float64, format.SampleRate.N(duration))
+	for i := range samples {
+		t := float64(i) / float64(format.SampleRate)
+		value := math.Sin(2*math.Pi*frequency*t) * math.Exp(-clickDecayPerSecond*t)
+		samples[i] = [2]float64{value, value}
+	}
+	return samples
+}
+
+// plays the samples once
+type samplesStreamer struct {
+	samples [][2]float64
+	pos     int
+}
+
+func (s *samplesStreamer) Stream(samples [][2]float64) (n int, ok bool) {
+	for n < len(samples) && s.pos < len(s.samples) {
+		samples[n] = s.samples[s.pos]
+		n++
+		s.pos++
+	}
+	return n, n > 0
+}
+
+func (s *samplesStreamer) Err() error {
+	return nil
+}
+
+// the tick played on each hit, at the volume
+func newHitSound(format beep.Format, volume float64) [][2]float64 {
+	samples := synthesizeClick(format, hitSoundFrequency, hitSoundDuration)
+	for i := range samples {
+		samples[i][0] *= volume
+		samples[i][1] *= volume
+	}
+	return samples
+}
+
+type scheduledClick struct {
+	timeMs int
+	accent bool
+	volume float64
+}
+
+// clicks at times in the song, mixed in with the song so they stay in time with it.
+// the track starts at startMs, which is negative to click before the song starts
+type clickTrack struct {
+	format  beep.Format
+	startMs int
+	clicks  []scheduledClick // sorted by time
+	click   [][2]float64
+	accent  [][2]float64
+	next    int // the first click that hasn't finished
+	pos     int
+}
+
+func newClickTrack(format beep.Format, startMs int, clicks []scheduledClick) *clickTrack {
+	clicks = append([]scheduledClick(nil), clicks...)
+	sort.SliceStable(clicks, func(i, j int) bool {
+		return clicks[i].timeMs < clicks[j].timeMs
+	})
+	return &clickTrack{
+		format:  format,
+		startMs: startMs,
+		clicks:  clicks,
+		click:   synthesizeClick(format, clickFrequency, clickDuration),
+		accent:  synthesizeClick(format, accentFrequency, clickDuration),
+	}
+}
+
+// the sample that the click starts at
+func (c *clickTrack) clickSample(click scheduledClick) int {
+	return c.format.SampleRate.N(time.Duration(click.timeMs-c.startMs) * time.Millisecond)
+}
+
+// never finishes, so it can be mixed with a song of any length
+func (c *clickTrack) Stream(samples [][2]float64) (n int, ok bool) {
+	for i := range samples {
+		samples[i] = [2]float64{}
+	}
+
+	end := c.pos + len(samples)
+	for c.next < len(c.clicks) && c.clickSample(c.clicks[c.next])+len(c.click) <= c.pos {
+		c.next++
+	}
+	for i := c.next; i < len(c.clicks); i++ {
+		click := c.clicks[i]
+		start := c.clickSample(click)
+		if start >= end {
+			break
+		}
+		sound := c.click
+		if click.accent {
+			sound = c.accent
+		}
+		for p := max(start, c.pos); p < start+len(sound) && p < end; p++ {
+			samples[p-c.pos][0] += sound[p-start][0] * click.volume
+			samples[p-c.pos][1] += sound[p-start][1] * click.volume
+		}
+	}
+	c.pos = end
+	return len(samples), true
+}
+
+func (c *clickTrack) Err() error {
+	return nil
+}
+
+// a click on every beat, with the first beat of each measure accented
+func metronomeClicks(beatLines []beatLineTime, volume float64) []scheduledClick {
+	clicks := make([]scheduledClick, len(beatLines))
+	for i, bl := range beatLines {
+		clicks[i] = scheduledClick{bl.timeMs, bl.kind == measureLine, volume}
+	}
+	return clicks
+}
+
+// the length of a beat and the beats in a measure at the time
+func beatsAt(beatLines []beatLineTime, timeMs int) (beatMs int, beats int) {
+	i := sort.Search(len(beatLines), func(i int) bool {
+		return beatLines[i].timeMs > timeMs
+	}) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i+1 >= len(beatLines) {
+		i = len(beatLines) - 2
+	}
+	if i < 0 {
+		return defaultCountInBeatMs, defaultCountInBeats
+	}
+	beatMs = beatLines[i+1].timeMs - beatLines[i].timeMs
+	if beatMs <= 0 {
+		return defaultCountInBeatMs, defaultCountInBeats
+	}
+
+	// count the beats from the start of the measure to the start of the next one
+	start := i
+	for start > 0 && beatLines[start].kind != measureLine {
+		start--
+	}
+	beats = 1
+	for j := start + 1; j < len(beatLines) && beatLines[j].kind != measureLine; j++ {
+		beats++
+	}
+	if beatLines[start].kind != measureLine || beats < 1 {
+		beats = defaultCountInBeats
+	}
+	return beatMs, beats
+}
+
+// one measure of clicks that ends at the start of the measure that the first note is in
+func countInClicks(beatLines []beatLineTime, firstNoteMs int, volume float64) []scheduledClick {
+	i := sort.Search(len(beatLines), func(i int) bool {
+		return beatLines[i].timeMs > firstNoteMs
+	}) - 1
+	for i >= 0 && beatLines[i].kind != measureLine {
+		i--
+	}
+	measureStartMs := firstNoteMs
+	if i >= 0 {
+		measureStartMs = beatLines[i].timeMs
+	}
+
+	beatMs, beats := beatsAt(beatLines, measureStartMs)
+	return measureOfClicks(measureStartMs-beats*beatMs, beatMs, beats, volume)
+}
+
+func measureOfClicks(startMs int, beatMs int, beats int, volume float64) []scheduledClick {
+	clicks := make([]scheduledClick, beats)
+	for i := range clicks {
+		clicks[i] = scheduledClick{startMs + i*beatMs, i == 0, volume}
+	}
+	return clicks
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/faiface/beep"
+)
+
+// 4/4 at 120 BPM, starting at startMs
+func testBeatLines(startMs int, measures int) []beatLineTime {
+	beatLines := make([]beatLineTime, 0)
+	for i := 0; i < measures*4; i++ {
+		kind := beatLine
+		if i%4 == 0 {
+			kind = measureLine
+		}
+		beatLines = append(beatLines, beatLineTime{startMs + i*500, kind})
+	}
+	return beatLines
+}
+
+func TestClickTrack_ClicksAtTheirTimes(t *testing.T) {
+	format := beep.Format{SampleRate: 1000, NumChannels: 2, Precision: 2}
+	track := newClickTrack(format, -100, []scheduledClick{{50, false, 0.5}, {-100, true, 1}})
+
+	samples := make([][2]float64, 300)
+	n, ok := track.Stream(samples[:120])
+	if n != 120 || !ok {
+		t.Fatal("Expected the click track to keep streaming")
+	}
+	track.Stream(samples[120:])
+
+	accent := synthesizeClick(format, accentFrequency, clickDuration)
+	click := synthesizeClick(format, clickFrequency, clickDuration)
+	if samples[1] != accent[1] {
+		t.Error("Expected the accented click at the start of the track, got", samples[1])
+	}
+	if samples[100] != [2]float64{0, 0} {
+		t.Error("Expected silence between clicks, got", samples[100])
+	}
+	// 50ms is 150 samples after the start at -100ms, and streamed across two calls
+	if samples[151][0] != click[1][0]*0.5 {
+		t.Error("Expected the click at half volume, got", samples[151])
+	}
+}
+
+func TestBeatsAt(t *testing.T) {
+	beatMs, beats := beatsAt(testBeatLines(1000, 3), 2200)
+	if beatMs != 500 || beats != 4 {
+		t.Errorf("Expected 4 beats of 500ms, got %d of %dms", beats, beatMs)
+	}
+
+	beatMs, beats = beatsAt(nil, 2200)
+	if beatMs != defaultCountInBeatMs || beats != defaultCountInBeats {
+		t.Errorf("Expected the default count-in without beat lines, got %d of %dms", beats, beatMs)
+	}
+}
+
+func TestCountInClicks(t *testing.T) {
+	// the first note is in the second measure, so the count-in is the first measure
+	clicks := countInClicks(testBeatLines(0, 4), 2500, 1)
+	expected := []scheduledClick{{0, true, 1}, {500, false, 1}, {1000, false, 1}, {1500, false, 1}}
+	if len(clicks) != len(expected) {
+		t.Fatal("Expected a measure of clicks, got", clicks)
+	}
+	for i := range expected {
+		if clicks[i] != expected[i] {
+			t.Errorf("Expected click %d to be %v, got %v", i, expected[i], clicks[i])
+		}
+	}
+
+	// the first note is in the first measure, so the count-in is before the song starts
+	clicks = countInClicks(testBeatLines(0, 4), 1000, 1)
+	if clicks[0].timeMs != -2000 || clicks[3].timeMs != -500 {
+		t.Error("Expected the count-in to be before the song, got", clicks)
+	}
+}
+
+func TestPlaySongModel_SongClicks(t *testing.T) {
+	chart := openCultOfPersonalityChart(t)
+	stngs := defaultSettings()
+	model := createModelFromChart(chart, parseTrackName("ExpertSingle"), stngs)
+	if len(model.songClicks()) != 0 {
+		t.Error("Expected no clicks by default")
+	}
+
+	stngs.metronome.enabled = true
+	stngs.countIn.enabled = true
+	clicks := model.songClicks()
+	if len(clicks) != len(model.beatLines)+4 {
+		t.Errorf("Expected a click on each of the %d beats and 4 for the count-in, got %d", len(model.beatLines), len(clicks))
+	}
+}
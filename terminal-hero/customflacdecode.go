@@ -0,0 +1,143 @@
+package main
+
+import (
+	"io"
+
+	"github.com/faiface/beep"
+	"github.com/mewkiz/flac"
+	"github.com/pkg/errors"
+)
+
+// modified version of the flac decoder to support any bit depth and more than 2 channels
+// original package from here:
+//  https://github.com/faiface/beep
+
+// DecodeFlac takes a ReadCloser containing audio data in FLAC format and returns a StreamSeekCloser,
+// which streams that audio. Seeking is only supported if rc is an io.Seeker.
+//
+// Do not close the supplied ReadCloser, instead, use the Close method of the returned
+// StreamSeekCloser when you want to release the resources.
+func DecodeFlac(rc io.ReadCloser) (s beep.StreamSeekCloser, format beep.Format, err error) {
+	defer func() {
+		if err != nil {
+			rc.Close()
+			err = errors.Wrap(err, "flac")
+		}
+	}()
+
+	d := &flacDecoder{closer: rc}
+	if rs, ok := rc.(io.ReadSeeker); ok {
+		d.stream, err = flac.NewSeek(rs)
+		d.seekEnabled = true
+	} else {
+		d.stream, err = flac.New(rc)
+	}
+	if err != nil {
+		return nil, beep.Format{}, err
+	}
+
+	format = beep.Format{
+		SampleRate:  beep.SampleRate(d.stream.Info.SampleRate),
+		NumChannels: int(d.stream.Info.NChannels),
+		Precision:   int(d.stream.Info.BitsPerSample+7) / 8,
+	}
+	return d, format, nil
+}
+
+type flacDecoder struct {
+	closer      io.Closer
+	stream      *flac.Stream
+	buf         [][2]float64
+	pos         int
+	err         error
+	seekEnabled bool
+}
+
+func (d *flacDecoder) Stream(samples [][2]float64) (n int, ok bool) {
+	if d.err != nil {
+		return 0, false
+	}
+	for n < len(samples) {
+		if len(d.buf) == 0 {
+			err := d.refill()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				d.err = errors.Wrap(err, "flac")
+				break
+			}
+		}
+		samples[n] = d.buf[0]
+		d.buf = d.buf[1:]
+		n++
+	}
+	d.pos += n
+	return n, n > 0
+}
+
+// decodes the next frame into the buffer. mono is played on both sides and only
+// the first two channels (front left and right) are played from multichannel audio
+func (d *flacDecoder) refill() error {
+	frame, err := d.stream.ParseNext()
+	if err != nil {
+		return err
+	}
+
+	n := len(frame.Subframes[0].Samples)
+	d.buf = make([][2]float64, n)
+
+	// the samples are signed and sign extended no matter the bit depth
+	scale := 1 / float64(int64(1)<<(d.stream.Info.BitsPerSample-1))
+	left := frame.Subframes[0].Samples
+	right := left
+	if len(frame.Subframes) > 1 {
+		right = frame.Subframes[1].Samples
+	}
+	for i := 0; i < n; i++ {
+		d.buf[i][0] = float64(left[i]) * scale
+		d.buf[i][1] = float64(right[i]) * scale
+	}
+	return nil
+}
+
+func (d *flacDecoder) Err() error {
+	return d.err
+}
+
+func (d *flacDecoder) Len() int {
+	return int(d.stream.Info.NSamples)
+}
+
+func (d *flacDecoder) Position() int {
+	return d.pos
+}
+
+func (d *flacDecoder) Seek(p int) error {
+	if !d.seekEnabled {
+		return errors.New("flac: seeking needs an io.Seeker")
+	}
+
+	pos, err := d.stream.Seek(uint64(p))
+	if err != nil {
+		return errors.Wrap(err, "flac")
+	}
+	d.pos = int(pos)
+	d.buf = nil
+	d.err = nil
+
+	// seeking goes to the start of the frame with the sample in it
+	if d.pos < p {
+		skipped := make([][2]float64, p-d.pos)
+		d.Stream(skipped)
+	}
+	return d.err
+}
+
+func (d *flacDecoder) Close() error {
+	err := d.closer.Close()
+	if err != nil {
+		return errors.Wrap(err, "flac")
+	}
+	return nil
+}
@@ -0,0 +1,507 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/faiface/beep"
+	"github.com/pion/opus"
+	"github.com/pion/opus/pkg/oggreader"
+	"github.com/pkg/errors"
+)
+
+const (
+	// opus is always decoded at 48kHz, and granule positions are in 48kHz samples
+	opusSampleRate = 48000
+	opusPrecision  = 2
+	// the longest opus packet is 120ms
+	opusMaxPacketSamples = opusSampleRate * 120 / 1000
+	// opus needs 80ms of audio decoded before the seek target to converge
+	opusSeekPreRoll = opusSampleRate * 80 / 1000
+	// how far from the end of the file to look for the last ogg page
+	oggLastPageSearchBytes = 64 * 1024
+)
+
+var oggPageCapturePattern = []byte("OggS")
+
+// DecodeOpus takes a ReadCloser containing audio data in ogg/opus format and returns a StreamSeekCloser,
+// which streams that audio. rc must be an io.Seeker to find the length of the audio.
+//
+// Do not close the supplied ReadCloser, instead, use the Close method of the returned
+// StreamSeekCloser when you want to release the resources.
+func DecodeOpus(rc io.ReadCloser) (s beep.StreamSeekCloser, format beep.Format, err error) {
+	defer func() {
+		if err != nil {
+			rc.Close()
+			err = errors.Wrap(err, "ogg/opus")
+		}
+	}()
+
+	rs, ok := rc.(io.ReadSeeker)
+	if !ok {
+		return nil, beep.Format{}, errors.New("the file must be seekable")
+	}
+
+	d := &opusDecoder{rs: rs, closer: rc}
+	header, err := d.restart()
+	if err != nil {
+		return nil, beep.Format{}, err
+	}
+
+	lastGranule, err := lastOggGranulePosition(rs)
+	if err != nil {
+		return nil, beep.Format{}, err
+	}
+	d.length = int(lastGranule) - d.preSkip
+	if d.length < 0 {
+		d.length = 0
+	}
+
+	// go back to the start after looking for the last page
+	_, err = d.restart()
+	if err != nil {
+		return nil, beep.Format{}, err
+	}
+
+	format = beep.Format{
+		SampleRate:  opusSampleRate,
+		NumChannels: int(header.Channels),
+		Precision:   opusPrecision,
+	}
+	return d, format, nil
+}
+
+type opusDecoder struct {
+	rs      io.ReadSeeker
+	closer  io.Closer
+	ogg     *oggreader.OggReader
+	decoder opus.Decoder
+
+	// set for multistream files, which only have the front left and right channels decoded
+	streamCount int
+	streams     map[int]*opusStream
+	left, right opusChannelSource
+
+	channels int // channels that are decoded, which is 1 or 2
+	preSkip  int // samples at the start of the stream that aren't part of the audio
+	out      []float32
+	pcm      []float32 // decoded samples that haven't been streamed, interleaved
+	skip     int       // decoded samples that are thrown away, for the pre-skip and seeking
+	pos      int
+	length   int
+	err      error
+}
+
+// reads the file from the beginning
+func (d *opusDecoder) restart() (*oggreader.OggHeader, error) {
+	_, err := d.rs.Seek(0, io.SeekStart)
+	if err != nil {
+		return nil, err
+	}
+
+	ogg, header, err := oggreader.NewWith(d.rs)
+	if err != nil {
+		return nil, err
+	}
+
+	d.channels = 2
+	if header.Channels == 1 {
+		d.channels = 1
+	}
+	mapping := ogg.ChannelMapping()
+	d.streamCount = int(mapping.StreamCount)
+	if d.streamCount > 1 {
+		err = d.initializeStreams(header, mapping)
+	} else {
+		d.decoder, err = opus.NewDecoderWithOutput(opusSampleRate, d.channels)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	d.ogg = ogg
+	d.preSkip = int(header.PreSkip)
+	d.out = make([]float32, opusMaxPacketSamples*d.channels)
+	d.pcm = nil
+	d.skip = d.preSkip
+	d.pos = 0
+	d.err = nil
+	return header, nil
+}
+
+// reads the next audio packet, skipping the comment header
+func (d *opusDecoder) nextPacket() ([]byte, *oggreader.OggPageHeader, error) {
+	for {
+		packet, pageHeader, err := d.ogg.ParseNextPacket()
+		if err != nil {
+			return nil, nil, err
+		}
+		if !bytes.HasPrefix(packet, []byte("OpusTags")) {
+			return packet, pageHeader, nil
+		}
+	}
+}
+
+func (d *opusDecoder) decodePacket(packet []byte) error {
+	if d.streamCount > 1 {
+		return d.decodeMultistreamPacket(packet)
+	}
+	n, err := d.decoder.DecodeToFloat32(packet, d.out)
+	if err != nil {
+		return err
+	}
+	d.pcm = d.out[:n*d.channels]
+	return nil
+}
+
+func (d *opusDecoder) Stream(samples [][2]float64) (n int, ok bool) {
+	if d.err != nil {
+		return 0, false
+	}
+	for n < len(samples) && d.pos < d.length {
+		if len(d.pcm) == 0 {
+			packet, _, err := d.nextPacket()
+			if err == io.EOF {
+				break
+			}
+			if err == nil {
+				err = d.decodePacket(packet)
+			}
+			if err != nil {
+				d.err = errors.Wrap(err, "ogg/opus")
+				break
+			}
+			continue
+		}
+
+		if d.skip > 0 {
+			skipped := d.skip
+			if skipped > len(d.pcm)/d.channels {
+				skipped = len(d.pcm) / d.channels
+			}
+			d.pcm = d.pcm[skipped*d.channels:]
+			d.skip -= skipped
+			continue
+		}
+
+		// mono is played on both sides
+		samples[n][0] = float64(d.pcm[0])
+		samples[n][1] = float64(d.pcm[d.channels-1])
+		d.pcm = d.pcm[d.channels:]
+		d.pos++
+		n++
+	}
+	return n, n > 0
+}
+
+func (d *opusDecoder) Err() error {
+	return d.err
+}
+
+func (d *opusDecoder) Len() int {
+	return d.length
+}
+
+func (d *opusDecoder) Position() int {
+	return d.pos
+}
+
+// packets are skipped without decoding them until a little before p
+func (d *opusDecoder) Seek(p int) error {
+	if p < 0 || p > d.length {
+		return errors.Errorf("ogg/opus: seek position %d out of range [0, %d]", p, d.length)
+	}
+
+	_, err := d.restart()
+	if err != nil {
+		return errors.Wrap(err, "ogg/opus")
+	}
+
+	target := p + d.preSkip
+	start := 0 // the granule position that the first decoded packet starts at
+	for {
+		packet, pageHeader, err := d.nextPacket()
+		if err == io.EOF {
+			d.pos = d.length
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "ogg/opus")
+		}
+
+		// the granule position is the end of the last packet that finishes on the page
+		granule := pageHeader.GranulePosition
+		if granule != ^uint64(0) && int(granule) <= target-opusSeekPreRoll {
+			start = int(granule)
+			continue
+		}
+
+		err = d.decodePacket(packet)
+		if err != nil {
+			return errors.Wrap(err, "ogg/opus")
+		}
+		d.skip = target - start
+		d.pos = p
+		return nil
+	}
+}
+
+func (d *opusDecoder) Close() error {
+	err := d.closer.Close()
+	if err != nil {
+		return errors.Wrap(err, "ogg/opus")
+	}
+	return nil
+}
+
+// finds the granule position of the last page, which is the length of the stream
+// plus the pre-skip
+func lastOggGranulePosition(rs io.ReadSeeker) (uint64, error) {
+	size, err := rs.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+
+	searchStart := size - oggLastPageSearchBytes
+	if searchStart < 0 {
+		searchStart = 0
+	}
+	_, err = rs.Seek(searchStart, io.SeekStart)
+	if err != nil {
+		return 0, err
+	}
+	tail, err := io.ReadAll(rs)
+	if err != nil {
+		return 0, err
+	}
+
+	// the page header is "OggS", the version, the header type and then the granule position
+	for end := len(tail); end > 0; {
+		i := bytes.LastIndex(tail[:end], oggPageCapturePattern)
+		if i < 0 {
+			break
+		}
+		end = i
+		if i+14 > len(tail) || tail[i+4] != 0 {
+			continue
+		}
+		granule := binary.LittleEndian.Uint64(tail[i+6 : i+14])
+		if granule != ^uint64(0) {
+			return granule, nil
+		}
+	}
+	return 0, errors.New("no ogg page with a granule position found")
+}
+
+// one of the streams in a multistream file, which has 1 or 2 channels
+type opusStream struct {
+	decoder  opus.Decoder
+	channels int
+	out      []float32
+	pcm      []float32 // the samples of the last packet, interleaved
+}
+
+// where an output channel's samples come from in a multistream file
+type opusChannelSource struct {
+	stream  int // -1 if the channel is silent
+	channel int // the channel in the stream
+}
+
+// the channel mapping maps each output channel to a channel of the coupled (stereo) streams,
+// which come first, or to one of the mono streams
+func opusChannelSourceFor(mapping oggreader.OggChannelMapping, outputChannel int) opusChannelSource {
+	if outputChannel >= len(mapping.Mapping) || mapping.Mapping[outputChannel] == 255 {
+		return opusChannelSource{-1, 0}
+	}
+	index := int(mapping.Mapping[outputChannel])
+	coupled := int(mapping.CoupledCount)
+	if index < coupled*2 {
+		return opusChannelSource{index / 2, index % 2}
+	}
+	return opusChannelSource{coupled + index - coupled*2, 0}
+}
+
+// only the front left and right channels of multichannel audio are played. with channel mapping
+// family 1 the channels are in vorbis order, which has the center between them from 3 channels,
+// except for quadraphonic
+func (d *opusDecoder) initializeStreams(header *oggreader.OggHeader, mapping oggreader.OggChannelMapping) error {
+	frontRight := 1
+	if header.ChannelMap == 1 && header.Channels >= 3 && header.Channels != 4 {
+		frontRight = 2
+	}
+	d.left = opusChannelSourceFor(mapping, 0)
+	d.right = opusChannelSourceFor(mapping, frontRight)
+	d.channels = 2
+
+	d.streams = make(map[int]*opusStream)
+	for _, source := range []opusChannelSource{d.left, d.right} {
+		if source.stream < 0 || d.streams[source.stream] != nil {
+			continue
+		}
+		if source.stream >= d.streamCount {
+			return errors.Errorf("channel mapping to stream %d of %d", source.stream, d.streamCount)
+		}
+		channels := 1
+		if source.stream < int(mapping.CoupledCount) {
+			channels = 2
+		}
+		decoder, err := opus.NewDecoderWithOutput(opusSampleRate, channels)
+		if err != nil {
+			return err
+		}
+		d.streams[source.stream] = &opusStream{decoder, channels, make([]float32, opusMaxPacketSamples*channels), nil}
+	}
+	return nil
+}
+
+func (d *opusDecoder) decodeMultistreamPacket(packet []byte) error {
+	packets, err := splitOpusMultistreamPacket(packet, d.streamCount)
+	if err != nil {
+		return err
+	}
+
+	n := -1
+	for i, stream := range d.streams {
+		streamSamples, err := stream.decoder.DecodeToFloat32(packets[i], stream.out)
+		if err != nil {
+			return err
+		}
+		stream.pcm = stream.out[:streamSamples*stream.channels]
+		if n < 0 || streamSamples < n {
+			n = streamSamples
+		}
+	}
+	n = max(n, 0)
+
+	if cap(d.out) < n*2 {
+		d.out = make([]float32, n*2)
+	}
+	d.pcm = d.out[:n*2]
+	for i := 0; i < n; i++ {
+		d.pcm[i*2] = d.sample(d.left, i)
+		d.pcm[i*2+1] = d.sample(d.right, i)
+	}
+	return nil
+}
+
+func (d *opusDecoder) sample(source opusChannelSource, i int) float32 {
+	if source.stream < 0 {
+		return 0
+	}
+	stream := d.streams[source.stream]
+	return stream.pcm[i*stream.channels+source.channel]
+}
+
+// every stream in a multistream packet but the last is self-delimited (RFC 6716 appendix B), which
+// adds the length of the last frame. it's taken out so each stream can be decoded as a normal packet
+func splitOpusMultistreamPacket(packet []byte, streamCount int) ([][]byte, error) {
+	packets := make([][]byte, 0, streamCount)
+	for i := 0; i < streamCount-1; i++ {
+		streamPacket, size, err := undelimitOpusPacket(packet)
+		if err != nil {
+			return nil, err
+		}
+		packets = append(packets, streamPacket)
+		packet = packet[size:]
+	}
+	return append(packets, packet), nil
+}
+
+// reads the self-delimited packet at the start of data, and returns it as a normal packet
+// along with how many bytes of data it took up
+func undelimitOpusPacket(data []byte) ([]byte, int, error) {
+	if len(data) == 0 {
+		return nil, 0, errors.New("multistream packet is too short")
+	}
+	toc := data[0]
+	header := []byte{toc} // what comes before the frames in the normal packet
+	offset := 1
+	framesLength := 0
+
+	// each length is added to the frames' length, and kept in the normal packet if keep is set
+	readLength := func(keep bool) error {
+		length, size, err := opusFrameLength(data[offset:])
+		if err != nil {
+			return err
+		}
+		if keep {
+			header = append(header, data[offset:offset+size]...)
+		}
+		offset += size
+		framesLength += length
+		return nil
+	}
+
+	switch toc & 3 {
+	case 0:
+		if err := readLength(false); err != nil {
+			return nil, 0, err
+		}
+	case 1:
+		// two frames of the same length
+		if err := readLength(false); err != nil {
+			return nil, 0, err
+		}
+		framesLength *= 2
+	case 2:
+		if err := readLength(true); err != nil {
+			return nil, 0, err
+		}
+		if err := readLength(false); err != nil {
+			return nil, 0, err
+		}
+	case 3:
+		if len(data) < 2 {
+			return nil, 0, errors.New("multistream packet is too short")
+		}
+		frameCount := int(data[1] & 0x3f)
+		vbr := data[1]&0x80 != 0
+		padded := data[1]&0x40 != 0
+		offset = 2
+		padding := 0
+		for padded {
+			if offset >= len(data) {
+				return nil, 0, errors.New("multistream packet is too short")
+			}
+			padding += min(int(data[offset]), 254)
+			padded = data[offset] == 255
+			offset++
+		}
+		header = append(header, data[1:offset]...)
+
+		if vbr {
+			for i := 0; i < frameCount; i++ {
+				if err := readLength(i < frameCount-1); err != nil {
+					return nil, 0, err
+				}
+			}
+		} else {
+			if err := readLength(false); err != nil {
+				return nil, 0, err
+			}
+			framesLength *= frameCount
+		}
+		framesLength += padding
+	}
+
+	end := offset + framesLength
+	if end > len(data) {
+		return nil, 0, errors.New("multistream packet is too short")
+	}
+	return append(header, data[offset:end]...), end, nil
+}
+
+// frame lengths are 1 byte, or 2 bytes from 252
+func opusFrameLength(data []byte) (int, int, error) {
+	if len(data) == 0 {
+		return 0, 0, errors.New("multistream packet is too short")
+	}
+	if data[0] < 252 {
+		return int(data[0]), 1, nil
+	}
+	if len(data) < 2 {
+		return 0, 0, errors.New("multistream packet is too short")
+	}
+	return int(data[1])*4 + int(data[0]), 2, nil
+}
@@ -21,6 +21,11 @@ import (
 // db := openGrDbConnection()
 // db.getVerifiedSongScores() // returns only verified song scores
 // db.setSongScore(song, track, score)
+// db.recordSongPlay(song) // counts a play, whether or not it was passed
+// db.addToPlaylist(name, song) // creates the playlist if it doesn't exist
+// db.setCareerStars(profile, career, chartHash, stars) // keeps the most stars
+// db.setTrackIntensities(chartHash, intensities) // caches the analysis of each track
+// db.getChartIntensities(chartHash) // the cached analysis of one chart
 // db.close()
 
 type grDbConnection struct {
@@ -30,6 +35,18 @@ type grDbConnection struct {
 type grDbAccessor interface {
 	getVerifiedSongScores() (*map[string]songScore, error)
 	setSongScore(s song, track string, newScore int, notesHit int, totalNotes int) error
+	recordSongPlay(s song) error
+	getSongPlays() (map[string]songPlays, error)
+	getPlaylists() ([]playlist, error)
+	addToPlaylist(name string, s song) error
+	removeFromPlaylist(name string, chartHash string) error
+	swapPlaylistSongs(name string, chartHash1 string, chartHash2 string) error
+	updatePlaylistSongPath(chartHash string, relativePath string) error
+	getCareerProgress(profile string, career string) (map[string]int, error)
+	setCareerStars(profile string, career string, chartHash string, stars int) error
+	getTrackIntensities() (map[string]map[string]float64, error)
+	getChartIntensities(chartHash string) (map[string]float64, bool, error)
+	setTrackIntensities(chartHash string, intensities map[string]float64) error
 	close() error
 }
 
@@ -52,6 +69,19 @@ type trackScore struct {
 	Fingerprint string // fingerprint to prevent cheating
 }
 
+// how often a song has been played. the map keys are chart hashes
+type songPlays struct {
+	PlayCount  int
+	LastPlayed int64 // unix time, or 0 if never played
+}
+
+// the songs are stored by chart hash, so they're still found after they're moved.
+// the relative path is where the song was last seen
+type playlist struct {
+	Name  string
+	Songs []song // in playlist order
+}
+
 func (ts trackScore) percentage() float64 {
 	if ts.TotalNotes == 0 {
 		return 0
@@ -223,6 +253,221 @@ func (conn grDbConnection) setSongScore(s song, track string, newScore int, note
 	return err
 }
 
+func (conn grDbConnection) recordSongPlay(s song) error {
+	songId, err := conn.addSongIfDoesntExist(s)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.db.Exec("UPDATE Songs SET PlayCount=PlayCount+1, LastPlayed=? WHERE Id=?", time.Now().Unix(), songId)
+	return err
+}
+
+func (conn grDbConnection) getSongPlays() (map[string]songPlays, error) {
+	rows, err := conn.db.Query("SELECT ChartHash,PlayCount,LastPlayed FROM Songs WHERE PlayCount > 0")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string]songPlays)
+	for rows.Next() {
+		var chartHash string
+		var plays songPlays
+		err = rows.Scan(&chartHash, &plays.PlayCount, &plays.LastPlayed)
+		if err != nil {
+			return nil, err
+		}
+		result[chartHash] = plays
+	}
+	return result, rows.Err()
+}
+
+// the playlists in the order they were created
+func (conn grDbConnection) getPlaylists() ([]playlist, error) {
+	rows, err := conn.db.Query(`SELECT p.Name, s.ChartHash, s.Name, s.RelativePath FROM Playlists p
+		JOIN PlaylistSongs s ON s.PlaylistId = p.Id ORDER BY p.Id, s.Position`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	playlists := []playlist{}
+	for rows.Next() {
+		var name string
+		var s song
+		err = rows.Scan(&name, &s.ChartHash, &s.Name, &s.RelativePath)
+		if err != nil {
+			return nil, err
+		}
+		if len(playlists) == 0 || playlists[len(playlists)-1].Name != name {
+			playlists = append(playlists, playlist{name, []song{}})
+		}
+		last := &playlists[len(playlists)-1]
+		last.Songs = append(last.Songs, s)
+	}
+	return playlists, rows.Err()
+}
+
+// adds the song to the end of the playlist. songs that are already in the playlist stay where they are
+func (conn grDbConnection) addToPlaylist(name string, s song) error {
+	_, err := conn.db.Exec("INSERT OR IGNORE INTO Playlists (Name) VALUES (?)", name)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.db.Exec(`INSERT OR IGNORE INTO PlaylistSongs (PlaylistId, ChartHash, Name, RelativePath, Position)
+		SELECT p.Id, ?, ?, ?, COALESCE((SELECT MAX(Position) FROM PlaylistSongs WHERE PlaylistId = p.Id), 0) + 1
+		FROM Playlists p WHERE p.Name = ?`, s.ChartHash, s.Name, s.RelativePath, name)
+	return err
+}
+
+// removes the song from the playlist, and the playlist once it's empty
+func (conn grDbConnection) removeFromPlaylist(name string, chartHash string) error {
+	_, err := conn.db.Exec("DELETE FROM PlaylistSongs WHERE ChartHash=? AND PlaylistId=(SELECT Id FROM Playlists WHERE Name=?)",
+		chartHash, name)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.db.Exec("DELETE FROM Playlists WHERE Name=? AND NOT EXISTS (SELECT 1 FROM PlaylistSongs WHERE PlaylistId=Playlists.Id)",
+		name)
+	return err
+}
+
+func (conn grDbConnection) swapPlaylistSongs(name string, chartHash1 string, chartHash2 string) error {
+	tx, err := conn.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	positions := [2]int{}
+	for i, chartHash := range []string{chartHash1, chartHash2} {
+		row := tx.QueryRow(`SELECT s.Position FROM PlaylistSongs s JOIN Playlists p ON s.PlaylistId = p.Id
+			WHERE p.Name=? AND s.ChartHash=?`, name, chartHash)
+		err = row.Scan(&positions[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	for i, chartHash := range []string{chartHash2, chartHash1} {
+		_, err = tx.Exec("UPDATE PlaylistSongs SET Position=? WHERE ChartHash=? AND PlaylistId=(SELECT Id FROM Playlists WHERE Name=?)",
+			positions[i], chartHash, name)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// remembers where a song that was moved is now, so it's found quickly next time
+func (conn grDbConnection) updatePlaylistSongPath(chartHash string, relativePath string) error {
+	_, err := conn.db.Exec("UPDATE PlaylistSongs SET RelativePath=? WHERE ChartHash=?", relativePath, chartHash)
+	return err
+}
+
+// the stars of each song that the profile has passed in the career. the map keys are chart hashes
+func (conn grDbConnection) getCareerProgress(profile string, career string) (map[string]int, error) {
+	rows, err := conn.db.Query("SELECT ChartHash,Stars FROM CareerSongs WHERE Profile=? AND Career=?", profile, career)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string]int)
+	for rows.Next() {
+		var chartHash string
+		var stars int
+		err = rows.Scan(&chartHash, &stars)
+		if err != nil {
+			return nil, err
+		}
+		result[chartHash] = stars
+	}
+	return result, rows.Err()
+}
+
+// marks the song as passed in the career. fewer stars than before don't replace the old ones
+func (conn grDbConnection) setCareerStars(profile string, career string, chartHash string, stars int) error {
+	_, err := conn.db.Exec(`INSERT INTO CareerSongs (Profile, Career, ChartHash, Stars) VALUES (?, ?, ?, ?)
+		ON CONFLICT(Profile, Career, ChartHash) DO UPDATE SET Stars=MAX(Stars, excluded.Stars)`,
+		profile, career, chartHash, stars)
+	return err
+}
+
+// the cached intensity ratings of each chart's tracks. the map keys are chart hashes, then track names
+func (conn grDbConnection) getTrackIntensities() (map[string]map[string]float64, error) {
+	rows, err := conn.db.Query("SELECT ChartHash,TrackName,Intensity FROM TrackIntensities")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string]map[string]float64)
+	for rows.Next() {
+		var chartHash, trackName string
+		var intensity float64
+		err = rows.Scan(&chartHash, &trackName, &intensity)
+		if err != nil {
+			return nil, err
+		}
+		if result[chartHash] == nil {
+			result[chartHash] = make(map[string]float64)
+		}
+		if trackName != unratedChartTrackName {
+			result[chartHash][trackName] = intensity
+		}
+	}
+	return result, rows.Err()
+}
+
+// the ratings of one chart's tracks. cached is false if the chart hasn't been analyzed
+func (conn grDbConnection) getChartIntensities(chartHash string) (map[string]float64, bool, error) {
+	rows, err := conn.db.Query("SELECT TrackName,Intensity FROM TrackIntensities WHERE ChartHash=?", chartHash)
+	if err != nil {
+		return nil, false, err
+	}
+	defer rows.Close()
+
+	result := make(map[string]float64)
+	cached := false
+	for rows.Next() {
+		var trackName string
+		var intensity float64
+		err = rows.Scan(&trackName, &intensity)
+		if err != nil {
+			return nil, false, err
+		}
+		cached = true
+		if trackName != unratedChartTrackName {
+			result[trackName] = intensity
+		}
+	}
+	return result, cached, rows.Err()
+}
+
+// the track name of the row that marks a chart that couldn't be rated, so it isn't analyzed again
+const unratedChartTrackName = ""
+
+// the ratings are stored by chart hash, so a chart that changes is analyzed again. a chart
+// without any ratings is cached as unrated
+func (conn grDbConnection) setTrackIntensities(chartHash string, intensities map[string]float64) error {
+	if len(intensities) == 0 {
+		intensities = map[string]float64{unratedChartTrackName: -1}
+	}
+	for trackName, intensity := range intensities {
+		_, err := conn.db.Exec(`INSERT INTO TrackIntensities (ChartHash, TrackName, Intensity) VALUES (?, ?, ?)
+			ON CONFLICT(ChartHash, TrackName) DO UPDATE SET Intensity=excluded.Intensity`,
+			chartHash, trackName, intensity)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (conn grDbConnection) getTrackScore(songId int, trackName string) (int, error) {
 	row := conn.db.QueryRow("SELECT Score FROM TrackScores WHERE SongId=? AND TrackName=?", songId, trackName)
 	if row.Err() != nil {
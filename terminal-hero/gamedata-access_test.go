@@ -9,7 +9,7 @@ import (
 	"time"
 )
 
-const expectedTotalMigrations = 2
+const expectedTotalMigrations = 6
 
 func cultOfPersonalitySong() song {
 	return song{
@@ -185,6 +185,176 @@ func TestSetSongScore(t *testing.T) {
 	}
 }
 
+func TestRecordSongPlay(t *testing.T) {
+	db, err := openAndMigrateTestDb()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.destroy(t)
+
+	before := time.Now().Unix()
+	for i := 0; i < 2; i++ {
+		err = db.recordSongPlay(cultOfPersonalitySong())
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	plays, err := db.getSongPlays()
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual := plays[cultOfPersonalitySong().ChartHash]
+	if actual.PlayCount != 2 {
+		t.Errorf("Play count is %d, expected 2", actual.PlayCount)
+	}
+	if actual.LastPlayed < before {
+		t.Errorf("Last played at %d, expected at least %d", actual.LastPlayed, before)
+	}
+}
+
+func playlistSongNames(p playlist) []string {
+	names := make([]string, len(p.Songs))
+	for i, s := range p.Songs {
+		names[i] = s.Name
+	}
+	return names
+}
+
+func TestPlaylists(t *testing.T) {
+	db, err := openAndMigrateTestDb()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.destroy(t)
+
+	one := song{"hash1", "GH3/One", "One"}
+	slowRide := song{"hash2", "GH3/Slow Ride", "Slow Ride"}
+	for _, s := range []song{one, slowRide, one} {
+		err = db.addToPlaylist("Road Trip", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = db.addToPlaylist(favoritesPlaylistName, slowRide)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	playlists, err := db.getPlaylists()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(playlists) != 2 || playlists[0].Name != "Road Trip" || playlists[1].Name != favoritesPlaylistName {
+		t.Fatalf("Expected Road Trip and Favorites, got %+v", playlists)
+	}
+	if names := playlistSongNames(playlists[0]); len(names) != 2 || names[0] != "One" || names[1] != "Slow Ride" {
+		t.Error("Expected each song once in the order they were added, got", names)
+	}
+
+	err = db.swapPlaylistSongs("Road Trip", one.ChartHash, slowRide.ChartHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.updatePlaylistSongPath(one.ChartHash, "RB/One")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.removeFromPlaylist(favoritesPlaylistName, slowRide.ChartHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	playlists, err = db.getPlaylists()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(playlists) != 1 {
+		t.Fatalf("Expected the empty playlist to be removed, got %+v", playlists)
+	}
+	if names := playlistSongNames(playlists[0]); len(names) != 2 || names[0] != "Slow Ride" || names[1] != "One" {
+		t.Error("Expected the songs to be swapped, got", names)
+	}
+	if playlists[0].Songs[1].RelativePath != "RB/One" {
+		t.Error("Expected the song's path to be updated, got", playlists[0].Songs[1].RelativePath)
+	}
+}
+
+func TestCareerStars(t *testing.T) {
+	db, err := openAndMigrateTestDb()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.destroy(t)
+
+	for _, stars := range []int{3, 5, 4} {
+		err = db.setCareerStars("default", "GH3", "hash1", stars)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = db.setCareerStars("other", "GH3", "hash2", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	progress, err := db.getCareerProgress("default", "GH3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(progress) != 1 || progress["hash1"] != 5 {
+		t.Error("Expected only the profile's best stars, 5, got", progress)
+	}
+}
+
+func TestTrackIntensities(t *testing.T) {
+	db, err := openAndMigrateTestDb()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.destroy(t)
+
+	err = db.setTrackIntensities("hash1", map[string]float64{"ExpertSingle": 4.2, "EasySingle": 0.7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// analyzing the chart again replaces the old ratings
+	err = db.setTrackIntensities("hash1", map[string]float64{"ExpertSingle": 4.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	intensities, err := db.getTrackIntensities()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(intensities["hash1"]) != 2 || intensities["hash1"]["ExpertSingle"] != 4.5 {
+		t.Error("Expected the latest rating of each track, got", intensities)
+	}
+}
+
+func TestTrackIntensities_CachesUnratedCharts(t *testing.T) {
+	db, err := openAndMigrateTestDb()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.destroy(t)
+
+	err = db.setTrackIntensities("hash1", map[string]float64{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	intensities, err := db.getTrackIntensities()
+	if err != nil {
+		t.Fatal(err)
+	}
+	unrated, ok := intensities["hash1"]
+	if !ok || len(unrated) != 0 {
+		t.Error("Expected the chart to be cached without any ratings, got", intensities)
+	}
+}
+
 func TestSetLowerScore_DoesNotChangeScore(t *testing.T) {
 	db, err := openAndMigrateTestDb()
 	if err != nil {
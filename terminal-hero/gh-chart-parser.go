@@ -2,7 +2,10 @@ package main
 
 import (
 	"bufio"
+	"errors"
+	"fmt"
 	"io"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -15,6 +18,23 @@ type Chart struct {
 	SongMetadata SongMetadata
 	SyncTrack    []SyncTrackElement
 	Tracks       map[string][]Note
+
+	// global events from the [Events] section, like sections and lyrics
+	Events []ChartEvent
+
+	// non-note events in each track, like star power phrases (S) and solos (E)
+	TrackEvents map[string][]ChartEvent
+
+	// names of the sections in the order they appear in the file, including empty ones
+	Sections []string
+
+	// denominators of TS events that specify one, keyed by tick.
+	// the value is the exponent of 2 (2 = quarter note)
+	TimeSignatureDenominators map[int]int
+
+	// elements that the parser doesn't interpret, keyed by section.
+	// these are kept so that the chart can be written without losing data
+	UnknownElements map[string][]ChartElement
 }
 
 type SyncTrackElement struct {
@@ -27,6 +47,42 @@ type SongMetadata struct {
 	Name       string
 	Offset     int
 	Resolution int
+
+	// string values have their quotes removed
+	Artist       string
+	Charter      string
+	Album        string
+	Year         string
+	Genre        string
+	Player2      string
+	Difficulty   int
+	PreviewStart float64
+	PreviewEnd   float64
+	MediaType    string
+
+	MusicStream  string
+	GuitarStream string
+	RhythmStream string
+	BassStream   string
+	DrumStream   string
+	KeysStream   string
+	VocalStream  string
+	CrowdStream  string
+
+	// every element in the [Song] section in file order
+	Fields []ChartElement
+}
+
+// a non-note event like "E solo" or "S 2 384"
+type ChartEvent struct {
+	TimeStamp int
+	Type      string // E for events, S for special phrases like star power
+	Value     string // everything after the type
+}
+
+// the text of an E event without its quotes
+func (e ChartEvent) Text() string {
+	return unquoteChartValue(e.Value)
 }
 
 type Note struct {
@@ -38,27 +94,21 @@ type Note struct {
 	ExtraData int64
 }
 
+func (c *Chart) HandleChartSection(section string) error {
+	c.Sections = append(c.Sections, section)
+	return nil
+}
+
+func (c *Chart) addUnknownElement(section string, element ChartElement) {
+	c.UnknownElements[section] = append(c.UnknownElements[section], element)
+}
+
 func (c *Chart) HandleChartElement(section string, element ChartElement) error {
 	switch section {
 	case "Song":
 		//println("Song", element.LeftValue, element.RightValue)
-		switch element.LeftValue {
-		case "Name":
-			c.SongMetadata.Name = element.RightValue
-		case "Offset":
-			num, err := strconv.ParseInt(element.RightValue, 10, 32)
-			if err != nil {
-				return err
-			}
-
-			c.SongMetadata.Offset = int(num)
-		case "Resolution":
-			num, err := strconv.ParseInt(element.RightValue, 10, 32)
-			if err != nil {
-				return err
-			}
-			c.SongMetadata.Resolution = int(num)
-		}
+		c.SongMetadata.Fields = append(c.SongMetadata.Fields, element)
+		return c.SongMetadata.setField(element.LeftValue, element.RightValue)
 	case "SyncTrack":
 		timeStamp, err := strconv.ParseInt(element.LeftValue, 10, 32)
 		if err != nil {
@@ -66,11 +116,21 @@ func (c *Chart) HandleChartElement(section string, element ChartElement) error {
 		}
 
 		split := strings.Split(element.RightValue, " ")
+		if len(split) < 2 {
+			return errors.New("expected a sync track type and value")
+		}
 		syncType := split[0]
 		syncVal, err := strconv.ParseInt(split[1], 10, 32)
 		if err != nil {
 			return err
 		}
+		if syncType == "TS" && len(split) > 2 {
+			denominator, err := strconv.ParseInt(split[2], 10, 32)
+			if err != nil {
+				return err
+			}
+			c.TimeSignatureDenominators[int(timeStamp)] = int(denominator)
+		}
 		element := SyncTrackElement{int(timeStamp), syncType, int(syncVal)}
 		c.SyncTrack = append(c.SyncTrack, element)
 	default:
@@ -81,10 +141,26 @@ func (c *Chart) HandleChartElement(section string, element ChartElement) error {
 
 		split := strings.Split(element.RightValue, " ")
 
+		if split[0] == "E" && section == "Events" {
+			c.Events = append(c.Events, ChartEvent{int(timeStamp), split[0], strings.Join(split[1:], " ")})
+			return nil
+		}
+
+		if section == "Events" || (split[0] != "N" && split[0] != "E" && split[0] != "S") {
+			c.addUnknownElement(section, element)
+			return nil
+		}
+
 		if split[0] != "N" {
+			event := ChartEvent{int(timeStamp), split[0], strings.Join(split[1:], " ")}
+			c.TrackEvents[section] = append(c.TrackEvents[section], event)
 			return nil
 		}
 
+		if len(split) < 3 {
+			return errors.New("expected N <type> <length>")
+		}
+
 		noteType, err := strconv.ParseInt(split[1], 10, 32)
 		if err != nil {
 			return err
@@ -107,19 +183,148 @@ func (c *Chart) HandleChartElement(section string, element ChartElement) error {
 	return nil
 }
 
+func (m *SongMetadata) stringFields() map[string]*string {
+	return map[string]*string{
+		"Name":         &m.Name,
+		"Artist":       &m.Artist,
+		"Charter":      &m.Charter,
+		"Album":        &m.Album,
+		"Year":         &m.Year,
+		"Genre":        &m.Genre,
+		"Player2":      &m.Player2,
+		"MediaType":    &m.MediaType,
+		"MusicStream":  &m.MusicStream,
+		"GuitarStream": &m.GuitarStream,
+		"RhythmStream": &m.RhythmStream,
+		"BassStream":   &m.BassStream,
+		"DrumStream":   &m.DrumStream,
+		"KeysStream":   &m.KeysStream,
+		"VocalStream":  &m.VocalStream,
+		"CrowdStream":  &m.CrowdStream,
+	}
+}
+
+func (m *SongMetadata) setField(key string, rawValue string) error {
+	if field, ok := m.stringFields()[key]; ok {
+		*field = unquoteChartValue(rawValue)
+		if key == "Year" {
+			// years are usually written as ", 2008"
+			*field = strings.TrimPrefix(*field, ", ")
+		}
+		return nil
+	}
+
+	switch key {
+	case "Offset":
+		num, err := strconv.ParseInt(rawValue, 10, 32)
+		if err != nil {
+			return err
+		}
+
+		m.Offset = int(num)
+	case "Resolution":
+		num, err := strconv.ParseInt(rawValue, 10, 32)
+		if err != nil {
+			return err
+		}
+		m.Resolution = int(num)
+	case "Difficulty":
+		// optional fields are ignored when they can't be parsed
+		num, err := strconv.ParseInt(rawValue, 10, 32)
+		if err == nil {
+			m.Difficulty = int(num)
+		}
+	case "PreviewStart":
+		num, err := strconv.ParseFloat(rawValue, 64)
+		if err == nil {
+			m.PreviewStart = num
+		}
+	case "PreviewEnd":
+		num, err := strconv.ParseFloat(rawValue, 64)
+		if err == nil {
+			m.PreviewEnd = num
+		}
+	}
+	return nil
+}
+
+// the value of the known field formatted as a string, or false if the key isn't a known field
+func (m *SongMetadata) fieldValue(key string) (string, bool) {
+	if field, ok := m.stringFields()[key]; ok {
+		return *field, true
+	}
+
+	switch key {
+	case "Offset":
+		return strconv.Itoa(m.Offset), true
+	case "Resolution":
+		return strconv.Itoa(m.Resolution), true
+	case "Difficulty":
+		return strconv.Itoa(m.Difficulty), true
+	case "PreviewStart":
+		return strconv.FormatFloat(m.PreviewStart, 'f', -1, 64), true
+	case "PreviewEnd":
+		return strconv.FormatFloat(m.PreviewEnd, 'f', -1, 64), true
+	}
+	return "", false
+}
+
+// Get returns the unquoted value of any [Song] field in the chart, including ones without their own struct field
+func (m *SongMetadata) Get(key string) (string, bool) {
+	for _, field := range m.Fields {
+		if field.LeftValue != key {
+			continue
+		}
+		if value, ok := m.fieldValue(key); ok {
+			return value, true
+		}
+		return unquoteChartValue(field.RightValue), true
+	}
+	return "", false
+}
+
+func unquoteChartValue(value string) string {
+	if len(value) >= 2 && strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
 func ParseF(reader io.Reader) (*Chart, error) {
+	return parseWithLineHandler(reader, nil)
+}
+
+// the line handler, if there is one, sees every line before it's parsed
+func parseWithLineHandler(reader io.Reader, lineHandler chartLineHandler) (*Chart, error) {
 	chart := &Chart{}
 	chart.Tracks = make(map[string][]Note)
 	chart.SyncTrack = make([]SyncTrackElement, 0)
+	chart.TimeSignatureDenominators = make(map[int]int)
+	chart.TrackEvents = make(map[string][]ChartEvent)
+	chart.UnknownElements = make(map[string][]ChartElement)
 
-	err := parseInternal(reader, chart)
+	err := parseInternal(reader, chart, lineHandler)
 	if err != nil {
 		return nil, err
 	}
 
+	chart.sortByTick()
 	return chart, nil
 }
 
+// charts with notes or sync events out of order still play in order. the sort is stable
+// so that elements at the same tick keep the order of the file
+func (c *Chart) sortByTick() {
+	sort.SliceStable(c.SyncTrack, func(i, j int) bool {
+		return c.SyncTrack[i].TimeStamp < c.SyncTrack[j].TimeStamp
+	})
+	for _, notes := range c.Tracks {
+		sort.SliceStable(notes, func(i, j int) bool {
+			return notes[i].TimeStamp < notes[j].TimeStamp
+		})
+	}
+}
+
 type ChartElement struct {
 	LeftValue  string
 	RightValue string
@@ -129,56 +334,117 @@ type ChartElementHandler interface {
 	HandleChartElement(section string, element ChartElement) error
 }
 
-func parseInternal(reader io.Reader, handler ChartElementHandler) error {
-	openedSquare := false
-	squareContent := ""
-	//openedCurly := false
-	bufferedReader := bufio.NewReader(reader)
-	for {
+// optionally implemented by a ChartElementHandler to be notified of each section header
+type ChartSectionHandler interface {
+	HandleChartSection(section string) error
+}
 
-		ru, _, err := bufferedReader.ReadRune()
-		if err != nil {
-			if err == io.EOF {
-				break
-			}
-			return err
+// sees every line of the chart, including the ones that aren't elements. used by the linter
+type chartLineHandler interface {
+	handleChartLine(lineNumber int, line chartLine)
+}
+
+// ChartParseError is returned when a line of a chart can't be parsed
+type ChartParseError struct {
+	Section string
+	Line    int // 1 based
+	Text    string
+	Err     error
+}
+
+func (e *ChartParseError) Error() string {
+	if e.Section == "" {
+		return fmt.Sprintf("line %d %q: %v", e.Line, e.Text, e.Err)
+	}
+	return fmt.Sprintf("line %d in [%s] %q: %v", e.Line, e.Section, e.Text, e.Err)
+}
+
+func (e *ChartParseError) Unwrap() error {
+	return e.Err
+}
+
+type chartLineKind int
+
+const (
+	chartLineBlank chartLineKind = iota
+	chartLineSection
+	chartLineOpen
+	chartLineClose
+	chartLineElement
+	chartLineInvalid
+)
+
+type chartLine struct {
+	kind    chartLineKind
+	text    string // the line without surrounding whitespace
+	section string // for section lines
+	element ChartElement
+}
+
+// splits a line of a .chart file into its parts. used by both the parser and the linter
+func tokenizeChartLine(line string) chartLine {
+	text := strings.TrimSpace(strings.TrimPrefix(line, "\uFEFF"))
+	switch {
+	case text == "":
+		return chartLine{kind: chartLineBlank}
+	case text == "{":
+		return chartLine{kind: chartLineOpen, text: text}
+	case text == "}":
+		return chartLine{kind: chartLineClose, text: text}
+	case strings.HasPrefix(text, "["):
+		if !strings.HasSuffix(text, "]") {
+			return chartLine{kind: chartLineInvalid, text: text}
 		}
+		return chartLine{kind: chartLineSection, text: text, section: text[1 : len(text)-1]}
+	}
 
-		switch ru {
-		case '[':
-			openedSquare = true
-			squareContent = ""
-		case ']':
-			openedSquare = false
-		case '{':
-			//openedCurly = true
-		case '}':
-			//openedCurly = false
-		case '\t':
-			leftVal, err := bufferedReader.ReadString('=')
-			if err != nil {
-				return err
-			}
-			leftStr := strings.TrimSpace(leftVal[:len(leftVal)-1])
+	left, right, found := strings.Cut(text, "=")
+	if !found {
+		return chartLine{kind: chartLineInvalid, text: text}
+	}
+	return chartLine{
+		kind:    chartLineElement,
+		text:    text,
+		element: ChartElement{strings.TrimSpace(left), strings.TrimSpace(right)},
+	}
+}
 
-			rightVal, err := bufferedReader.ReadString('\n')
-			if err != nil {
-				return err
-			}
-			rightStr := strings.TrimSpace(rightVal)
+// reads the chart line by line and passes each element to the handler
+func parseInternal(reader io.Reader, handler ChartElementHandler, lineHandler chartLineHandler) error {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	section := ""
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+		line := tokenizeChartLine(scanner.Text())
+		if lineHandler != nil {
+			lineHandler.handleChartLine(lineNumber, line)
+		}
 
-			err = handler.HandleChartElement(squareContent, ChartElement{leftStr, rightStr})
-			if err != nil {
-				return err
+		var err error
+		switch line.kind {
+		case chartLineSection:
+			section = line.section
+			if sectionHandler, ok := handler.(ChartSectionHandler); ok {
+				err = sectionHandler.HandleChartSection(section)
 			}
-		default:
-			if openedSquare {
-				squareContent += string(ru)
+		case chartLineElement:
+			if section == "" {
+				err = errors.New("element is outside of a section")
+			} else {
+				err = handler.HandleChartElement(section, line.element)
 			}
+		case chartLineInvalid:
+			err = errors.New("expected a [section] or key = value")
 		}
 
+		if err != nil {
+			return &ChartParseError{section, lineNumber, line.text, err}
+		}
 	}
-	return nil
+	return scanner.Err()
 }
 
 func timeElapsed(ticksElapsed float64, bpmm float64, resolution float64) float64 {
@@ -186,47 +452,12 @@ func timeElapsed(ticksElapsed float64, bpmm float64, resolution float64) float64
 }
 
 func getNotesWithRealTimestamps(chart *Chart, trackName string) []Note {
-	var result []Note = make([]Note, 0)
-
-	expert := chart.Tracks[trackName]
-	syncTrack := chart.SyncTrack
-	currentTime := float64(0)
-	currentTick := 0
-	currentBpm := float64(120000)
-	for len(expert) > 0 {
-		note := expert[0]
-		expert = expert[1:]
-
-		for len(syncTrack) > 0 {
-			sync := syncTrack[0]
-
-			if sync.TimeStamp > note.TimeStamp {
-				// sync event happens after note
-				break
-			}
-			if sync.Type != "B" {
-				// ignoring TS events for now
-				syncTrack = syncTrack[1:]
-				continue
-			}
-			ticksElapsed := sync.TimeStamp - currentTick
-
-			// advance currentTime and currentTick
-			currentTime += timeElapsed(float64(ticksElapsed), currentBpm, float64(chart.SongMetadata.Resolution))
-			currentTick = sync.TimeStamp
-			currentBpm = float64(sync.Value)
-
-			syncTrack = syncTrack[1:]
-		}
-
-		ticksElapsed := note.TimeStamp - currentTick
-		if ticksElapsed > 0 {
-			currentTime += timeElapsed(float64(ticksElapsed), currentBpm, float64(chart.SongMetadata.Resolution))
-			currentTick = note.TimeStamp
-		}
-
-		heldNoteTime := int64(timeElapsed(float64(note.ExtraData), currentBpm, float64(chart.SongMetadata.Resolution)))
-		result = append(result, Note{int(currentTime), note.RawNoteType, heldNoteTime})
+	tempoMap := NewTempoMap(chart)
+	notes := chart.Tracks[trackName]
+	result := make([]Note, len(notes))
+	for i, note := range notes {
+		heldNoteTime := int64(tempoMap.DurationMs(note.TimeStamp, note.ExtraData))
+		result[i] = Note{int(tempoMap.TickToMs(note.TimeStamp)), note.RawNoteType, heldNoteTime}
 	}
 	return result
 }
@@ -1,10 +1,23 @@
 package main
 
 import (
+	"errors"
 	"os"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 )
 
+// parses a chart written in the test
+func parseTestChart(t *testing.T, chartText string) *Chart {
+	chart, err := ParseF(strings.NewReader(chartText))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return chart
+}
+
 func TestParseMetadata(t *testing.T) {
 	file, err := os.Open("sample-songs/cult-of-personality.chart")
 	if err != nil {
@@ -98,3 +111,163 @@ func TestExpertSingle(t *testing.T) {
 		t.Error("Expected last note to be", expectedLastNote, "got", lastNote)
 	}
 }
+
+func TestParseAllSongMetadata(t *testing.T) {
+	chartText := `[Song]
+{
+	Name = "Test Song"
+	Artist = "Test Artist"
+	Charter = "Someone"
+	Album = "Test Album"
+	Year = ", 2008"
+	Offset = 0
+	Resolution = 480
+	Player2 = bass
+	Difficulty = 4
+	PreviewStart = 12.5
+	Genre = "rock"
+	MusicStream = "song.ogg"
+	GuitarStream = "guitar.ogg"
+	CustomField = "custom"
+}
+`
+	chart, err := ParseF(strings.NewReader(chartText))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := chart.SongMetadata
+	if m.Name != "Test Song" || m.Artist != "Test Artist" || m.Charter != "Someone" || m.Album != "Test Album" {
+		t.Error("Expected unquoted name, artist, charter and album, got", m.Name, m.Artist, m.Charter, m.Album)
+	}
+	if m.Year != "2008" {
+		t.Error("Expected year to be 2008, got", m.Year)
+	}
+	if m.Resolution != 480 || m.Difficulty != 4 || m.PreviewStart != 12.5 {
+		t.Error("Expected resolution 480, difficulty 4 and preview start 12.5, got", m.Resolution, m.Difficulty, m.PreviewStart)
+	}
+	if m.Player2 != "bass" || m.Genre != "rock" || m.MusicStream != "song.ogg" || m.GuitarStream != "guitar.ogg" {
+		t.Error("Expected player2, genre and streams to be parsed, got", m.Player2, m.Genre, m.MusicStream, m.GuitarStream)
+	}
+
+	value, ok := m.Get("CustomField")
+	if !ok || value != "custom" {
+		t.Error("Expected custom field to be kept, got", value, ok)
+	}
+	if _, ok := m.Get("Icon"); ok {
+		t.Error("Expected missing field to not be found")
+	}
+	if len(m.Fields) != 14 {
+		t.Error("Expected 14 fields, got", len(m.Fields))
+	}
+}
+
+func TestParseEvents(t *testing.T) {
+	chartText := `[Events]
+{
+	0 = E "section Intro"
+	768 = E "lyric Hello"
+}
+[ExpertSingle]
+{
+	0 = N 0 0
+	0 = S 2 384
+	192 = E solo
+	384 = E soloend
+}
+`
+	chart, err := ParseF(strings.NewReader(chartText))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedEvents := []ChartEvent{{0, "E", `"section Intro"`}, {768, "E", `"lyric Hello"`}}
+	if !reflect.DeepEqual(expectedEvents, chart.Events) {
+		t.Errorf("Expected events %v, got %v", expectedEvents, chart.Events)
+	}
+	if chart.Events[0].Text() != "section Intro" {
+		t.Error("Expected event text without quotes, got", chart.Events[0].Text())
+	}
+
+	expectedTrackEvents := []ChartEvent{{0, "S", "2 384"}, {192, "E", "solo"}, {384, "E", "soloend"}}
+	if !reflect.DeepEqual(expectedTrackEvents, chart.TrackEvents["ExpertSingle"]) {
+		t.Errorf("Expected track events %v, got %v", expectedTrackEvents, chart.TrackEvents["ExpertSingle"])
+	}
+	if len(chart.Tracks["ExpertSingle"]) != 1 {
+		t.Error("Expected track events to not be notes, got", chart.Tracks["ExpertSingle"])
+	}
+}
+
+func TestParseErrors_HaveLineInformation(t *testing.T) {
+	testCases := []struct {
+		name    string
+		chart   string
+		section string
+		line    int
+		text    string
+	}{
+		{"sync track with one field", "[Song]\n{\n\tResolution = 192\n}\n[SyncTrack]\n{\n\t0 = B\n}\n", "SyncTrack", 7, "0 = B"},
+		{"note with too few fields", "[ExpertSingle]\n{\n\t0 = N 0 0\n\t192 = N 1\n}\n", "ExpertSingle", 4, "192 = N 1"},
+		{"missing equals", "[ExpertSingle]\n{\n\t0 N 0 0\n}\n", "ExpertSingle", 3, "0 N 0 0"},
+		{"bad number", "[Song]\n{\n\tResolution = abc\n}\n", "Song", 3, "Resolution = abc"},
+		{"bad tick", "[ExpertSingle]\r\n{\r\n\tx = N 0 0\r\n}\r\n", "ExpertSingle", 3, "x = N 0 0"},
+		{"element outside section", "\t0 = N 0 0\n", "", 1, "0 = N 0 0"},
+	}
+
+	for _, tc := range testCases {
+		chart, err := ParseF(strings.NewReader(tc.chart))
+		if chart != nil {
+			t.Errorf("%s: expected no chart", tc.name)
+		}
+
+		var parseErr *ChartParseError
+		if !errors.As(err, &parseErr) {
+			t.Errorf("%s: expected a ChartParseError, got %v", tc.name, err)
+			continue
+		}
+		if parseErr.Section != tc.section || parseErr.Line != tc.line || parseErr.Text != tc.text {
+			t.Errorf("%s: expected [%s] line %d %q, got [%s] line %d %q",
+				tc.name, tc.section, tc.line, tc.text, parseErr.Section, parseErr.Line, parseErr.Text)
+		}
+		if parseErr.Err == nil || !strings.Contains(err.Error(), "line "+strconv.Itoa(tc.line)) {
+			t.Errorf("%s: expected the error message to contain the line, got %v", tc.name, err)
+		}
+	}
+}
+
+func TestParse_SpaceIndentedAndCRLF(t *testing.T) {
+	chartText := "\uFEFF[Song]\r\n{\r\n  Resolution = 192\r\n}\r\n[ExpertSingle]\r\n{\r\n  0 = N 1 0\r\n  192 = N 2 96\r\n}\r\n"
+
+	chart, err := ParseF(strings.NewReader(chartText))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if chart.SongMetadata.Resolution != 192 {
+		t.Error("Expected resolution to be 192, got", chart.SongMetadata.Resolution)
+	}
+	expected := []Note{{0, 1, 0}, {192, 2, 96}}
+	if !reflect.DeepEqual(expected, chart.Tracks["ExpertSingle"]) {
+		t.Errorf("Expected %v, got %v", expected, chart.Tracks["ExpertSingle"])
+	}
+}
+
+func TestParse_SortsNotesAndSyncTrackByTick(t *testing.T) {
+	chartText := "[SyncTrack]\n{\n\t768 = B 140000\n\t0 = B 120000\n\t0 = TS 4\n}\n" +
+		"[ExpertSingle]\n{\n\t192 = N 1 0\n\t0 = N 2 0\n\t192 = N 0 0\n}\n"
+
+	chart, err := ParseF(strings.NewReader(chartText))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedSync := []SyncTrackElement{{0, "B", 120000}, {0, "TS", 4}, {768, "B", 140000}}
+	if !reflect.DeepEqual(expectedSync, chart.SyncTrack) {
+		t.Errorf("Expected %v, got %v", expectedSync, chart.SyncTrack)
+	}
+	// notes at the same tick keep their order
+	expectedNotes := []Note{{0, 2, 0}, {192, 1, 0}, {192, 0, 0}}
+	if !reflect.DeepEqual(expectedNotes, chart.Tracks["ExpertSingle"]) {
+		t.Errorf("Expected %v, got %v", expectedNotes, chart.Tracks["ExpertSingle"])
+	}
+}
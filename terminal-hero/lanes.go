@@ -0,0 +1,90 @@
+package main
+
+import "strings"
+
+const (
+	fiveFretLaneCount = 5
+	sixFretLaneCount  = 6
+)
+
+// six-fret (Guitar Hero Live) tracks have 3 black and 3 white frets. the lanes
+// alternate black and white so that each black fret is next to the white one
+// below it, which makes barre chords (both frets of a pair) easy to read
+var sixFretLaneForNoteType = map[int]int{
+	3: 0, // black 1
+	0: 1, // white 1
+	4: 2, // black 2
+	1: 3, // white 2
+	8: 4, // black 3
+	2: 5, // white 3
+}
+
+// the keys for each lane. black frets are on the number row and white frets are under them
+const fiveFretKeys = "12345"
+const sixFretKeys = "1q2w3e"
+
+func isSixFretTrack(track trackName) bool {
+	return strings.HasPrefix(track.instrument, "GHL")
+}
+
+func laneCountForTrack(track trackName) int {
+	if isSixFretTrack(track) {
+		return sixFretLaneCount
+	}
+	return fiveFretLaneCount
+}
+
+// the lane for a raw guitar note type. ok is false for note types that aren't
+// notes, like the forced (5) and tap (6) flags
+func laneForNoteType(track trackName, rawNoteType int) (lane int, isOpenNote bool, ok bool) {
+	if rawNoteType == guitarOpenNoteType {
+		return openNoteColorIndex, true, true
+	}
+
+	if isSixFretTrack(track) {
+		lane, ok := sixFretLaneForNoteType[rawNoteType]
+		return lane, false, ok
+	}
+
+	if rawNoteType >= 0 && rawNoteType < fiveFretLaneCount {
+		return rawNoteType, false, true
+	}
+	return 0, false, false
+}
+
+// the raw guitar note type for the lane, the reverse of laneForNoteType
+func noteTypeForLane(track trackName, lane int) int {
+	if isSixFretTrack(track) {
+		for noteType, l := range sixFretLaneForNoteType {
+			if l == lane {
+				return noteType
+			}
+		}
+	}
+	return lane
+}
+
+func laneKeys(laneCount int) string {
+	if laneCount == sixFretLaneCount {
+		return sixFretKeys
+	}
+	return fiveFretKeys
+}
+
+// the lane that the key plays, if any
+func laneForKey(laneCount int, key string) (int, bool) {
+	if len(key) != 1 {
+		return 0, false
+	}
+	lane := strings.Index(laneKeys(laneCount), key)
+	return lane, lane >= 0
+}
+
+// the text shown inside a note, which is the key that plays it
+func laneLabel(laneCount int, lane int) string {
+	return string(laneKeys(laneCount)[lane])
+}
+
+func isBlackFretLane(laneCount int, lane int) bool {
+	return laneCount == sixFretLaneCount && lane%2 == 0
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const sixFretChart = `[Song]
+{
+	Resolution = 192
+}
+[SyncTrack]
+{
+	0 = B 120000
+}
+[ExpertGHLGuitar]
+{
+	0 = N 3 0
+	0 = N 0 0
+	192 = N 8 0
+	192 = N 5 0
+	384 = N 7 0
+	576 = N 2 0
+}
+`
+
+func TestLaneForNoteType_SixFret(t *testing.T) {
+	track := parseTrackName("ExpertGHLGuitar")
+
+	if laneCountForTrack(track) != 6 {
+		t.Fatal("Expected 6 lanes, got", laneCountForTrack(track))
+	}
+
+	testCases := []struct {
+		rawNoteType int
+		lane        int
+		isOpenNote  bool
+		ok          bool
+	}{
+		{3, 0, false, true}, // black 1
+		{0, 1, false, true}, // white 1
+		{4, 2, false, true},
+		{1, 3, false, true},
+		{8, 4, false, true}, // black 3
+		{2, 5, false, true},
+		{7, openNoteColorIndex, true, true},
+		{5, 0, false, false}, // forced flag
+		{6, 0, false, false}, // tap flag
+	}
+
+	for _, tc := range testCases {
+		lane, isOpenNote, ok := laneForNoteType(track, tc.rawNoteType)
+		if ok != tc.ok || (ok && (lane != tc.lane || isOpenNote != tc.isOpenNote)) {
+			t.Errorf("Expected note type %d to be lane %d open %v ok %v, got %d %v %v",
+				tc.rawNoteType, tc.lane, tc.isOpenNote, tc.ok, lane, isOpenNote, ok)
+		}
+	}
+}
+
+func TestLaneForNoteType_FiveFretSkipsFlags(t *testing.T) {
+	track := parseTrackName("ExpertSingle")
+
+	if _, _, ok := laneForNoteType(track, 5); ok {
+		t.Error("Expected the forced flag to not be a note")
+	}
+	if lane, _, ok := laneForNoteType(track, 4); !ok || lane != 4 {
+		t.Error("Expected note type 4 to be lane 4, got", lane, ok)
+	}
+}
+
+func TestLaneForKey(t *testing.T) {
+	if lane, ok := laneForKey(6, "w"); !ok || lane != 3 {
+		t.Error("Expected w to be white 2 on six-fret, got", lane, ok)
+	}
+	if lane, ok := laneForKey(5, "5"); !ok || lane != 4 {
+		t.Error("Expected 5 to be orange on five-fret, got", lane, ok)
+	}
+	if _, ok := laneForKey(6, "5"); ok {
+		t.Error("Expected 5 to not be a six-fret key")
+	}
+	if _, ok := laneForKey(5, "q"); ok {
+		t.Error("Expected q to not be a five-fret key")
+	}
+}
+
+func TestPlayNote_SixFretBarreChord(t *testing.T) {
+	chart := parseTestChart(t, sixFretChart)
+	track := parseTrackName("ExpertGHLGuitar")
+	model := createModelFromChart(chart, track, defaultSettings())
+	model.chartInfo.track = track
+
+	if len(model.realTimeNotes) != 5 {
+		t.Fatal("Expected the forced flag to be left out, got", len(model.realTimeNotes), "notes")
+	}
+	if len(model.viewModel.noteStates) != 6 {
+		t.Fatal("Expected 6 note states, got", len(model.viewModel.noteStates))
+	}
+
+	model = initializeModelToStrumLineTime(model, 0)
+
+	// black 1 and white 1 make a barre chord
+	model = model.PlayNote(0, 0)
+	model = model.PlayNote(1, 5)
+
+	if !model.realTimeNotes[0].played || !model.realTimeNotes[1].played {
+		t.Error("Expected the barre chord to be played")
+	}
+	if model.playStats.notesHitIndividials != 2 {
+		t.Error("Expected notesHit to be 2, got", model.playStats.notesHitIndividials)
+	}
+}
+
+func TestFretboardView_SixFret(t *testing.T) {
+	chart := parseTestChart(t, sixFretChart)
+	track := parseTrackName("ExpertGHLGuitar")
+	model := createModelFromChart(chart, track, defaultSettings())
+	model.chartInfo.track = track
+	model.settings.fretBoardHeight = 70
+	model.currentTimeMs = 1600
+	model = model.UpdateViewModel()
+
+	r := strings.Builder{}
+	model.CreateFretboardView(&r, gpSimpleNoteStyles, nil, nil, gpSimpleBeatLineStyles)
+	view := r.String()
+
+	for _, expected := range []string{"[1]", "(q)", "[3]", "(e)"} {
+		if !strings.Contains(view, expected) {
+			t.Errorf("Expected %s to be drawn, got\n%s", expected, view)
+		}
+	}
+
+	// 6 lanes of 5 characters
+	strumLine := strings.Split(view, "\n")[model.getStrumLineIndex()]
+	if !strings.Contains(strumLine, strings.Repeat("-----", 6)) {
+		t.Error("Expected the strum line to have 6 lanes, got", strumLine)
+	}
+}
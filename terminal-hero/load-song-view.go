@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
@@ -45,6 +46,11 @@ func (m loadSongModel) View() string {
 
 			ld.WriteString(loadSuccessString("chart"))
 			ld.WriteRune('\n')
+
+			if len(m.chart.lintProblems) > 0 {
+				ld.WriteString(lintWarningBadge(m.chart.lintProblems))
+				ld.WriteRune('\n')
+			}
 		}
 	} else {
 		ld.WriteString(m.spinner.View() + " " + loadingString("chart"))
@@ -83,6 +89,20 @@ func (m loadSongModel) View() string {
 	return sb.String()
 }
 
+// shows the number of chart problems and the first few of them
+func lintWarningBadge(problems []lintProblem) string {
+	const maxShown = 3
+	sb := strings.Builder{}
+	sb.WriteString(orangeTextStyle.Render(fmt.Sprintf("⚠ %d chart problems (run terminal-hero lint for details)", len(problems))))
+	for i, problem := range problems {
+		if i == maxShown {
+			break
+		}
+		sb.WriteString("\n  " + problem.String())
+	}
+	return sb.String()
+}
+
 func loadFailureString(errStr string) string {
 	return redTextStyle.Render("✕ Failed to " + errStr)
 }
@@ -30,6 +30,9 @@ type loadSongModel struct {
 	selectedInstrument *instrumentVm
 	backout            bool
 	speaker            soundPlayer
+	dbAccessor         grDbAccessor // caches the intensity ratings. nil to always analyze the chart
+	editing            bool         // the selected track will be opened in the chart editor instead of played
+	autoSelectTrack    string       // the full name of the track to pick without asking, if the chart has it
 }
 
 type loadedSoundEffectsMsg struct {
@@ -43,9 +46,11 @@ type loadedSongSoundsMsg struct {
 }
 
 type loadedChartMsg struct {
-	chart     *Chart
-	converted bool
-	err       error
+	chart        *Chart
+	converted    bool
+	lintProblems []lintProblem // warnings about the chart that didn't stop it from loading
+	intensities  map[string]trackIntensity
+	err          error
 }
 
 type trackName struct {
@@ -60,8 +65,9 @@ type instrumentVm struct {
 	tracks []trackName
 }
 type difficultyVm struct {
-	name  string
-	track trackName
+	track     trackName
+	intensity trackIntensity
+	analyzed  bool
 }
 
 func (i instrumentVm) Title() string {
@@ -72,6 +78,17 @@ func (i instrumentVm) Description() string {
 }
 func (i instrumentVm) FilterValue() string { return i.name }
 
+func (i difficultyVm) Title() string {
+	if !i.analyzed {
+		return i.track.Title()
+	}
+	return fmt.Sprintf("%s (intensity %s)", i.track.Title(), i.intensity)
+}
+func (i difficultyVm) Description() string {
+	return ""
+}
+func (i difficultyVm) FilterValue() string { return i.track.FilterValue() }
+
 func (i trackName) Title() string {
 	return getDifficultyDisplayName(i.difficulty)
 }
@@ -81,10 +98,10 @@ func (i trackName) Description() string {
 func (i trackName) FilterValue() string { return getDifficultyDisplayName(i.difficulty) }
 
 func (m loadSongModel) Init() tea.Cmd {
-	return tea.Batch(loadSongSoundsCmd(m.chartFolderPath, m.speaker), convertChartCmd(m.chartFolderPath), loadSongEffectsCmd(m.speaker), m.spinner.Tick)
+	return tea.Batch(loadSongSoundsCmd(m.chartFolderPath, m.speaker), convertChartCmd(m.chartFolderPath, m.dbAccessor), loadSongEffectsCmd(m.speaker), m.spinner.Tick)
 }
 
-func initialLoadModel(chartFolderPath string, stngs *settings, spkr soundPlayer) loadSongModel {
+func initialLoadModel(chartFolderPath string, stngs *settings, spkr soundPlayer, db grDbAccessor) loadSongModel {
 	s := spinner.New()
 	s.Spinner = spinner.Points
 	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
@@ -94,6 +111,7 @@ func initialLoadModel(chartFolderPath string, stngs *settings, spkr soundPlayer)
 		settings:        stngs,
 		spinner:         s,
 		speaker:         spkr,
+		dbAccessor:      db,
 	}
 }
 
@@ -113,7 +131,7 @@ func loadSongSoundsCmd(chartFolderPath string, spkr soundPlayer) tea.Cmd {
 }
 
 func isSupportedAudioFile(fileName string) bool {
-	return strings.HasSuffix(fileName, ".ogg") || strings.HasSuffix(fileName, ".wav")
+	return getAudioDecoderForFile(fileName) != nil
 }
 
 func loadInstrumentSoundFiles(instrument string, folderPath string, spkr soundPlayer) (playableSound[beep.StreamSeeker], error) {
@@ -124,7 +142,11 @@ func loadInstrumentSoundFiles(instrument string, folderPath string, spkr soundPl
 	}
 
 	sounds := make([]playableSound[beep.StreamSeekCloser], 0)
-	streams := make([]beep.Streamer, 0)
+	closeSounds := func() {
+		for _, sound := range sounds {
+			sound.soundStream.Close()
+		}
+	}
 	for _, file := range files {
 		if !isSupportedAudioFile(file.Name()) {
 			continue
@@ -134,54 +156,81 @@ func loadInstrumentSoundFiles(instrument string, folderPath string, spkr soundPl
 			filePath := filepath.Join(folderPath, file.Name())
 			stream, format, err := openAudioFileNonBuffered(filePath)
 			if err != nil {
+				closeSounds()
 				return playableSound[beep.StreamSeeker]{}, err
 			}
 
 			if len(sounds) > 0 {
 				if format.SampleRate != sounds[0].format.SampleRate {
+					stream.Close()
+					closeSounds()
 					return playableSound[beep.StreamSeeker]{}, errors.New("format mismatch for " + instrument + " " + filePath)
 				}
 			}
 
 			log.Info("Found " + instrument + " sound. " + fmt.Sprintf("len=%d -- %+v", stream.Len(), format) + " path=" + filePath)
 			sounds = append(sounds, playableSound[beep.StreamSeekCloser]{stream, format})
-			streams = append(streams, stream)
 		}
 	}
 	if len(sounds) == 0 {
 		return playableSound[beep.StreamSeeker]{}, nil
 	}
-	var mixedStreamer beep.Streamer
-	if len(sounds) == 1 {
-		mixedStreamer = sounds[0].soundStream
-	} else {
-		mixedStreamer = beep.Mix(streams...)
+
+	sources := make([]beep.StreamSeekCloser, len(sounds))
+	for i, sound := range sounds {
+		sources[i] = sound.soundStream
 	}
 
-	resampled := resampleIntoBuffer(spkr, mixedStreamer, sounds[0].format)
-	for _, sound := range sounds {
-		sound.soundStream.Close()
+	// the stems are decoded while they play, so only the speaker format is needed here
+	spkrFormat := spkr.resampleIfNeeded(sources[0], sounds[0].format).format
+	streamed, err := newStreamedSound(sources, sounds[0].format, spkrFormat)
+	if err != nil {
+		closeSounds()
+		return playableSound[beep.StreamSeeker]{}, err
 	}
 
-	return resampled, nil
+	return playableSound[beep.StreamSeeker]{streamed, spkrFormat}, nil
 }
 
 func loadSongSounds(chartFolderPath string, spkr soundPlayer) (songSounds, error) {
 	log.Info("loadSongSounds")
 
-	song, err := loadInstrumentSoundFiles(instrumentMisc, chartFolderPath, spkr)
+	// the stems that were opened are closed if a later one fails to load
+	loaded := []beep.StreamSeeker{}
+	succeeded := false
+	defer func() {
+		if !succeeded {
+			for _, stream := range loaded {
+				closeStreamSeeker(stream)
+			}
+		}
+	}()
+	load := func(instrument string) (playableSound[beep.StreamSeeker], error) {
+		sound, err := loadInstrumentSoundFiles(instrument, chartFolderPath, spkr)
+		if sound.soundStream != nil {
+			loaded = append(loaded, sound.soundStream)
+		}
+		return sound, err
+	}
+
+	song, err := load(instrumentMisc)
+	if err != nil {
+		return songSounds{}, err
+	}
+	guitar, err := load(instrumentGuitar)
 	if err != nil {
 		return songSounds{}, err
 	}
-	guitar, err := loadInstrumentSoundFiles(instrumentGuitar, chartFolderPath, spkr)
+	bass, err := load(instrumentBass)
 	if err != nil {
 		return songSounds{}, err
 	}
-	bass, err := loadInstrumentSoundFiles(instrumentBass, chartFolderPath, spkr)
+	drum, _ := load(instrumentDrums)
+	keys, err := load(instrumentKeys)
 	if err != nil {
 		return songSounds{}, err
 	}
-	drum, _ := loadInstrumentSoundFiles(instrumentDrums, chartFolderPath, spkr)
+	rhythm, err := load(instrumentRhythm)
 	if err != nil {
 		return songSounds{}, err
 	}
@@ -189,9 +238,12 @@ func loadSongSounds(chartFolderPath string, spkr soundPlayer) (songSounds, error
 	guitarVol := playableSound[*effects.Volume]{addVolumeControl(guitar.soundStream), guitar.format}
 	bassVol := playableSound[*effects.Volume]{addVolumeControl(bass.soundStream), bass.format}
 	drumVol := playableSound[*effects.Volume]{addVolumeControl(drum.soundStream), drum.format}
+	keysVol := playableSound[*effects.Volume]{addVolumeControl(keys.soundStream), keys.format}
+	rhythmVol := playableSound[*effects.Volume]{addVolumeControl(rhythm.soundStream), rhythm.format}
 
-	ss := songSounds{guitarVol, song, bassVol, drumVol}
+	ss := songSounds{guitarVol, song, bassVol, drumVol, keysVol, rhythmVol}
 
+	succeeded = true
 	return ss, nil
 }
 
@@ -208,13 +260,53 @@ func addVolumeControl(stream beep.Streamer) *effects.Volume {
 	return vol
 }
 
-func convertChartCmd(chartFolderPath string) tea.Cmd {
+func convertChartCmd(chartFolderPath string, db grDbAccessor) tea.Cmd {
 	return func() tea.Msg {
-		chart, converted, err := initializeChart(chartFolderPath)
-		return loadedChartMsg{chart, converted, err}
+		chart, converted, lintProblems, err := initializeChart(chartFolderPath)
+		var intensities map[string]trackIntensity
+		if err == nil {
+			intensities = loadChartIntensities(chart, chartFolderPath, db)
+		}
+		return loadedChartMsg{chart, converted, lintProblems, intensities, err}
 	}
 }
 
+// the chart is only analyzed if its ratings haven't been cached yet. only the ratings are
+// cached, so the other measurements are zero. a chart cached as unrated is analyzed again,
+// since it was just loaded
+func loadChartIntensities(chart *Chart, chartFolderPath string, db grDbAccessor) map[string]trackIntensity {
+	if db == nil {
+		return chartIntensities(chart)
+	}
+	chartHash, err := hashFileByPath(filepath.Join(chartFolderPath, "notes.chart"))
+	if err != nil {
+		log.Error("Failed to hash the chart", "path", chartFolderPath, "err", err)
+		return chartIntensities(chart)
+	}
+
+	cached, ok, err := db.getChartIntensities(chartHash)
+	if err != nil {
+		log.Error("Failed to get the song's intensity", "path", chartFolderPath, "err", err)
+	} else if ok && len(cached) > 0 {
+		result := make(map[string]trackIntensity)
+		for trackName, rating := range cached {
+			result[trackName] = trackIntensity{rating: rating}
+		}
+		return result
+	}
+
+	intensities := chartIntensities(chart)
+	ratings := make(map[string]float64)
+	for trackName, ti := range intensities {
+		ratings[trackName] = ti.rating
+	}
+	err = db.setTrackIntensities(chartHash, ratings)
+	if err != nil {
+		log.Error("Failed to save the song's intensity", "path", chartFolderPath, "err", err)
+	}
+	return intensities
+}
+
 func convertMidi(midiFilePath string) (string, error) {
 	mid2ChartFolderPath, err := getSubDataFolderPath(".mid2chart")
 	if err != nil {
@@ -246,7 +338,7 @@ func convertMidi(midiFilePath string) (string, error) {
 	return jarFilePath + " " + midiFilePath + " " + out.String(), err
 }
 
-func initializeChart(chartFolderPath string) (*Chart, bool, error) {
+func initializeChart(chartFolderPath string) (*Chart, bool, []lintProblem, error) {
 	notesFilePath := filepath.Join(chartFolderPath, "notes.chart")
 	chartFile, err := os.Open(notesFilePath)
 	convertedChart := false
@@ -255,30 +347,33 @@ func initializeChart(chartFolderPath string) (*Chart, bool, error) {
 			midFilePath := filepath.Join(chartFolderPath, "notes.mid")
 			_, midErr := os.Stat(midFilePath)
 			if midErr != nil {
-				return nil, false, errors.New("no notes.chart or notes.mid file found")
+				return nil, false, nil, errors.New("no notes.chart or notes.mid file found")
 			}
 			msg, err := convertMidi(midFilePath)
 			if err != nil {
-				return nil, false, errors.New("failed to convert midi: " + msg + " " + err.Error())
+				return nil, false, nil, errors.New("failed to convert midi: " + msg + " " + err.Error())
 			}
 
 			convertedChart = true
 
 			chartFile, err = os.Open(notesFilePath)
 			if err != nil {
-				return nil, convertedChart, errors.New("still no chart: " + err.Error())
+				return nil, convertedChart, nil, errors.New("still no chart: " + err.Error())
 			}
 		} else {
-			return nil, false, errors.New("failed to open chart: " + err.Error())
+			return nil, false, nil, errors.New("failed to open chart: " + err.Error())
 		}
 	}
 
-	chart, err := ParseF(chartFile)
+	chart, lintProblems, err := parseAndLintChart(chartFile)
 	chartFile.Close()
 	if err != nil {
-		return nil, convertedChart, errors.New("failed to parse chart: " + err.Error())
+		return nil, convertedChart, nil, errors.New("failed to parse chart: " + err.Error())
+	}
+	for _, problem := range lintProblems {
+		log.Info("chart lint " + notesFilePath + " " + problem.String())
 	}
-	return chart, convertedChart, nil
+	return chart, convertedChart, filterLintProblems(lintProblems, lintWarning), nil
 }
 
 func (m loadSongModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -296,7 +391,7 @@ func (m loadSongModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		if m.chart.err == nil {
 			selectTrackMenuList := list.New([]list.Item{}, createListDd(false), 0, 0)
-			selectTrackMenuList.SetSize(25, m.settings.fretBoardHeight-16)
+			selectTrackMenuList.SetSize(32, m.settings.fretBoardHeight-16)
 			selectTrackMenuList.SetShowStatusBar(false)
 			selectTrackMenuList.SetFilteringEnabled(false)
 			selectTrackMenuList.SetShowHelp(false)
@@ -306,6 +401,9 @@ func (m loadSongModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.menuList = &selectTrackMenuList
 
 			m = m.initializeMenuForSelectInstrument()
+			if m.autoSelectTrack != "" {
+				m = m.selectTrackAutomatically(m.autoSelectTrack)
+			}
 		}
 	case tea.KeyMsg:
 		switch msg.String() {
@@ -321,12 +419,12 @@ func (m loadSongModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						panic("selected track is not a instrumentVm " + to)
 					}
 				} else {
-					tn, ok := m.menuList.SelectedItem().(trackName)
+					dv, ok := m.menuList.SelectedItem().(difficultyVm)
 					if ok {
-						m.selectedTrack = &tn
+						m.selectedTrack = &dv.track
 					} else {
 						to := reflect.TypeOf(m.menuList.SelectedItem()).String()
-						panic("selected track is not a trackName " + to)
+						panic("selected track is not a difficultyVm " + to)
 					}
 				}
 			}
@@ -353,7 +451,7 @@ func (m loadSongModel) initializeMenuForSelectInstrument() loadSongModel {
 	i := 0
 	tracks := make([]trackName, len(m.chart.chart.Tracks))
 	for k := range m.chart.chart.Tracks {
-		tracks[i] = parseTrackName(k)
+		tracks[i] = parseChartTrackName(m.chart.chart, k)
 		i++
 	}
 
@@ -370,10 +468,27 @@ func (m loadSongModel) initializeMenuForSelectInstrument() loadSongModel {
 	return m
 }
 
+// picks the track if the chart has it, otherwise the instrument and difficulty are asked for as usual
+func (m loadSongModel) selectTrackAutomatically(fullTrackName string) loadSongModel {
+	for _, item := range m.menuList.Items() {
+		in := item.(instrumentVm)
+		for _, tn := range in.tracks {
+			if tn.fullTrackName == fullTrackName {
+				m.selectedInstrument = &in
+				m = m.initializeMenuForSelectDifficulty()
+				m.selectedTrack = &tn
+				return m
+			}
+		}
+	}
+	return m
+}
+
 func (m loadSongModel) initializeMenuForSelectDifficulty() loadSongModel {
 	listItems := make([]list.Item, len(m.selectedInstrument.tracks))
 	for i, track := range m.selectedInstrument.tracks {
-		listItems[i] = track
+		intensity, analyzed := m.chart.intensities[track.fullTrackName]
+		listItems[i] = difficultyVm{track, intensity, analyzed}
 	}
 
 	m.menuList.Title = "Select " + m.selectedInstrument.Title() + " Difficulty"
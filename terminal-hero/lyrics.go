@@ -0,0 +1,154 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// lyric phrases are shown until this long after their last syllable when they have no phrase_end
+const lyricPhraseDefaultEndMs = 1000
+
+type lyricSyllable struct {
+	timeMs   int
+	text     string
+	joinNext bool // no space before the next syllable, because they're part of the same word
+}
+
+type lyricPhrase struct {
+	startMs   int
+	endMs     int
+	syllables []lyricSyllable
+}
+
+// parses the lyric syllable from the text after "lyric ". ok is false for syllables
+// that aren't shown, like pitch slides (+)
+func parseLyricSyllable(text string) (syllable lyricSyllable, ok bool) {
+	// pitch and talkie markers
+	text = strings.TrimRight(strings.TrimSpace(text), "#^*%$")
+
+	joinNext := strings.HasSuffix(text, "-")
+	text = strings.TrimSuffix(text, "-")
+	// = is a hyphen that should be shown
+	text = strings.ReplaceAll(text, "=", "-")
+
+	if text == "" || text == "+" {
+		return lyricSyllable{}, false
+	}
+	return lyricSyllable{text: text, joinNext: joinNext}, true
+}
+
+// groups the lyric events of the chart into phrases using phrase_start and phrase_end
+func getLyricPhrases(chart *Chart, tempoMap *TempoMap) []lyricPhrase {
+	phrases := make([]lyricPhrase, 0)
+	var current *lyricPhrase
+
+	endPhrase := func(endMs int) {
+		if current == nil {
+			return
+		}
+		if len(current.syllables) > 0 {
+			if endMs < 0 {
+				endMs = current.syllables[len(current.syllables)-1].timeMs + lyricPhraseDefaultEndMs
+			}
+			current.endMs = endMs
+			phrases = append(phrases, *current)
+		}
+		current = nil
+	}
+
+	for _, event := range chart.Events {
+		if event.Type != "E" {
+			continue
+		}
+		timeMs := int(tempoMap.TickToMs(event.TimeStamp))
+		text := event.Text()
+
+		switch {
+		case text == "phrase_start":
+			endPhrase(timeMs)
+			current = &lyricPhrase{startMs: timeMs}
+		case text == "phrase_end":
+			endPhrase(timeMs)
+		case strings.HasPrefix(text, "lyric "):
+			syllable, ok := parseLyricSyllable(strings.TrimPrefix(text, "lyric "))
+			if !ok {
+				continue
+			}
+			if current == nil {
+				// lyrics outside of a phrase get their own phrase
+				current = &lyricPhrase{startMs: timeMs}
+			}
+			syllable.timeMs = timeMs
+			current.syllables = append(current.syllables, syllable)
+		}
+	}
+	endPhrase(-1)
+
+	return phrases
+}
+
+// the index of the phrase that is being sung at the time, or the next one if
+// none is. returns -1 when there are no more phrases
+func lyricPhraseIndexAt(phrases []lyricPhrase, timeMs int) int {
+	for i, phrase := range phrases {
+		if timeMs < phrase.endMs {
+			return i
+		}
+	}
+	return -1
+}
+
+// the index of the syllable that is being sung, or -1 if the phrase hasn't started
+func currentSyllableIndex(phrase lyricPhrase, timeMs int) int {
+	current := -1
+	for i, syllable := range phrase.syllables {
+		if syllable.timeMs > timeMs {
+			break
+		}
+		current = i
+	}
+	return current
+}
+
+var lyricsSungStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(pinkAccentColor))
+var lyricsCurrentStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(yellowAccentColor)).Bold(true).Underline(true)
+var lyricsNextPhraseStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#5c5c5c"))
+var lyricsStyle = lipgloss.NewStyle().Width(lyricsWidth).Padding(1, 0, 0, 0)
+
+const lyricsWidth = 30
+
+func renderLyricPhrase(phrase lyricPhrase, currentIndex int, nextPhrase bool) string {
+	r := strings.Builder{}
+	for i, syllable := range phrase.syllables {
+		switch {
+		case nextPhrase:
+			r.WriteString(lyricsNextPhraseStyle.Render(syllable.text))
+		case i < currentIndex:
+			r.WriteString(lyricsSungStyle.Render(syllable.text))
+		case i == currentIndex:
+			r.WriteString(lyricsCurrentStyle.Render(syllable.text))
+		default:
+			r.WriteString(syllable.text)
+		}
+		if !syllable.joinNext && i < len(phrase.syllables)-1 {
+			r.WriteString(" ")
+		}
+	}
+	return r.String()
+}
+
+// the current phrase with the syllable being sung highlighted, and the next phrase under it
+func lyricsView(phrases []lyricPhrase, timeMs int) string {
+	phraseIndex := lyricPhraseIndexAt(phrases, timeMs)
+	if phraseIndex < 0 {
+		return ""
+	}
+
+	phrase := phrases[phraseIndex]
+	r := renderLyricPhrase(phrase, currentSyllableIndex(phrase, timeMs), false)
+	if phraseIndex+1 < len(phrases) {
+		r += "\n" + renderLyricPhrase(phrases[phraseIndex+1], -1, true)
+	}
+	return lyricsStyle.Render(r)
+}
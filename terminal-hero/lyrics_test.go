@@ -0,0 +1,116 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const lyricsChart = `[Song]
+{
+	Resolution = 192
+}
+[SyncTrack]
+{
+	0 = B 120000
+}
+[Events]
+{
+	0 = E "phrase_start"
+	192 = E "lyric Hel-"
+	288 = E "lyric lo#"
+	384 = E "lyric +"
+	480 = E "lyric world"
+	576 = E "phrase_end"
+	768 = E "section Verse"
+	960 = E "lyric Ro=ck"
+	1152 = E "phrase_start"
+	1344 = E "lyric on$"
+}
+[ExpertSingle]
+{
+	192 = N 0 0
+}
+`
+
+func TestGetLyricPhrases(t *testing.T) {
+	chart := parseTestChart(t, lyricsChart)
+	phrases := getLyricPhrases(chart, NewTempoMap(chart))
+
+	if len(phrases) != 3 {
+		t.Fatalf("Expected 3 phrases, got %v", phrases)
+	}
+
+	first := phrases[0]
+	if first.startMs != 0 || first.endMs != 1500 {
+		t.Error("Expected the first phrase to be 0-1500ms, got", first.startMs, first.endMs)
+	}
+	expected := []lyricSyllable{{500, "Hel", true}, {750, "lo", false}, {1250, "world", false}}
+	if len(first.syllables) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, first.syllables)
+	}
+	for i := range expected {
+		if first.syllables[i] != expected[i] {
+			t.Errorf("Expected %v, got %v", expected[i], first.syllables[i])
+		}
+	}
+
+	// a lyric outside of a phrase is its own phrase until the next phrase_start
+	if phrases[1].endMs != 3000 || phrases[1].syllables[0].text != "Ro-ck" {
+		t.Error("Expected the implicit phrase to end at 3000ms, got", phrases[1])
+	}
+
+	// the last phrase has no phrase_end
+	if phrases[2].endMs != 3500+lyricPhraseDefaultEndMs {
+		t.Error("Expected the last phrase to end after its last syllable, got", phrases[2].endMs)
+	}
+}
+
+func TestCurrentSyllableIndex(t *testing.T) {
+	chart := parseTestChart(t, lyricsChart)
+	phrases := getLyricPhrases(chart, NewTempoMap(chart))
+
+	testCases := []struct {
+		timeMs        int
+		phraseIndex   int
+		syllableIndex int
+	}{
+		{0, 0, -1},
+		{600, 0, 0},
+		{800, 0, 1},
+		{1400, 0, 2},
+		{1600, 1, -1},
+		{3200, 2, -1},
+		{5000, -1, -1},
+	}
+
+	for _, tc := range testCases {
+		phraseIndex := lyricPhraseIndexAt(phrases, tc.timeMs)
+		if phraseIndex != tc.phraseIndex {
+			t.Errorf("Expected phrase %d at %dms, got %d", tc.phraseIndex, tc.timeMs, phraseIndex)
+			continue
+		}
+		if phraseIndex < 0 {
+			continue
+		}
+		if syllableIndex := currentSyllableIndex(phrases[phraseIndex], tc.timeMs); syllableIndex != tc.syllableIndex {
+			t.Errorf("Expected syllable %d at %dms, got %d", tc.syllableIndex, tc.timeMs, syllableIndex)
+		}
+	}
+}
+
+func TestLyricsView(t *testing.T) {
+	chart := parseTestChart(t, lyricsChart)
+	phrases := getLyricPhrases(chart, NewTempoMap(chart))
+
+	view := lyricsView(phrases, 800)
+	if !strings.Contains(view, "Hello world") {
+		t.Error("Expected the syllables of a word to be joined, got", view)
+	}
+	if !strings.Contains(view, "Ro-ck") {
+		t.Error("Expected the next phrase to be shown, got", view)
+	}
+
+	if lyricsView(phrases, 5000) != "" {
+		t.Error("Expected no lyrics after the last phrase")
+	}
+}
@@ -1,10 +1,12 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -23,18 +25,27 @@ const (
 	loadSong
 	playSong
 	statsScreen
+	editChart
+	setlistSummary
+	careerScreen
 )
 
 type mainModel struct {
-	state            sessionState
-	selectSongModel  selectSongModel
-	loadSongModel    loadSongModel
-	playSongModel    playSongModel
-	statsScreenModel statsScreenModel
-	songRootPath     string
-	dbAccessor       grDbAccessor
-	settings         *settings
-	speaker          *thSpeaker
+	state             sessionState
+	selectSongModel   selectSongModel
+	loadSongModel     loadSongModel
+	playSongModel     playSongModel
+	statsScreenModel  statsScreenModel
+	chartEditorModel  chartEditorModel
+	setlistSummary    setlistSummaryModel
+	setlist           *setlist // the queued songs, which are kept while going back to the song list
+	careerScreenModel careerScreenModel
+	careerSong        *careerSong // the career song being played, if it was picked from the career screen
+	career            *career     // resolved the first time the career screen loads, and reused after that
+	songRootPath      string
+	dbAccessor        grDbAccessor
+	settings          *settings
+	speaker           *thSpeaker
 }
 
 type settings struct {
@@ -43,30 +54,43 @@ type settings struct {
 	guitarLineTime  time.Duration
 	drumLineTime    time.Duration
 	strumTolerance  time.Duration
+	doubleKick      bool          // play the expert+ double kick notes in drum tracks
+	proDrums        bool          // cymbals and toms have to be hit with their own keys
+	audioOutput     string        // where the sound goes, one of audioOutputs
+	audioWavPath    string        // the file that the wav audio output writes to
+	hitSounds       clickSettings // a tick on each hit note
+	metronome       clickSettings // a click on each beat
+	countIn         clickSettings // a measure of clicks before the first note and after unpausing
+	songListOrder   songListOrder // how the song list is sorted and filtered. kept between songs
+	profile         string        // whose career progress is saved
+	careerFilePath  string        // the career's tiers. empty for career.json in the game data folder
+	// the songs that were picked at random most recently, which aren't picked again for a while
+	recentRandomPicks []string
 }
 
 func defaultSettings() *settings {
 	lineTime := 30 * time.Millisecond
 	strumTolerance := 100 * time.Millisecond
 	fretboardHeight := 35
-	return &settings{fretboardHeight, 38, lineTime, (lineTime * 3) / 2, strumTolerance}
+	clicks := clickSettings{false, defaultClickVolume}
+	return &settings{fretboardHeight, 38, lineTime, (lineTime * 3) / 2, strumTolerance, false, false, audioOutputAuto, "",
+		clicks, clicks, clicks, defaultSongListOrder(), "default", "", nil}
 }
 
-func initialMainModel() mainModel {
-	settings := defaultSettings()
-
+func initialMainModel(settings *settings) mainModel {
 	songRootPath, err := createAndGetSubDataFolder("Songs")
 	if err != nil {
 		panic(err)
 	}
 
-	spkr := thSpeaker{}
+	spkr := thSpeaker{outputKind: settings.audioOutput, outputWavPath: settings.audioWavPath}
 
 	return mainModel{
 		state:        initialLoad,
 		songRootPath: songRootPath,
 		settings:     settings,
 		speaker:      &spkr,
+		setlist:      &setlist{},
 	}
 }
 
@@ -95,6 +119,7 @@ func initializeDbCmd() tea.Cmd {
 
 func (m mainModel) onQuit() {
 	m.playSongModel.destroy()
+	m.chartEditorModel.destroy()
 	m.dbAccessor.close()
 }
 
@@ -119,7 +144,7 @@ func (m mainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			panic(msg.err)
 		}
 		m.dbAccessor = msg.dbAccessor
-		m.selectSongModel = initialSelectSongModel(m.songRootPath, m.dbAccessor, m.settings, m.speaker)
+		m.selectSongModel = initialSelectSongModel(m.songRootPath, m.dbAccessor, m.settings, m.speaker, m.setlist)
 		m.state = chooseSong
 		return m, m.selectSongModel.Init()
 	}
@@ -127,10 +152,18 @@ func (m mainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch m.state {
 	case chooseSong:
 		selectModel, cmd := m.selectSongModel.Update(msg)
+		if selectModel.(selectSongModel).openCareer {
+			return m.showCareerScreen("")
+		}
+		if selectModel.(selectSongModel).startSetlist {
+			m.setlist.start()
+			return m.loadSetlistSong()
+		}
 		selectedSong := selectModel.(selectSongModel).selectedSongPath
 		if selectedSong != "" {
 			ssPath := selectModel.(selectSongModel).selectedSongPath
-			loadModel := initialLoadModel(ssPath, m.settings, m.speaker)
+			loadModel := initialLoadModel(ssPath, m.settings, m.speaker, m.dbAccessor)
+			loadModel.editing = selectModel.(selectSongModel).editSelectedSong
 			lmCmd := loadModel.Init()
 			m.state = loadSong
 			m.loadSongModel = loadModel
@@ -143,8 +176,17 @@ func (m mainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		loadModel := lm.(loadSongModel)
 
 		if loadModel.backout {
+			if loadModel.songSounds != nil {
+				loadModel.songSounds.songSounds.close()
+			}
+			if m.careerSong != nil {
+				m.careerSong = nil
+				return m.showCareerScreen(loadModel.chartFolderPath)
+			}
+			// the rest of the setlist stays queued
+			m.setlist.stop()
 			m.state = chooseSong
-			m.selectSongModel = initialSelectSongModel(m.songRootPath, m.dbAccessor, m.settings, m.speaker)
+			m.selectSongModel = initialSelectSongModel(m.songRootPath, m.dbAccessor, m.settings, m.speaker, m.setlist)
 
 			initCmd := m.selectSongModel.Init()
 
@@ -155,7 +197,14 @@ func (m mainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				panic(err)
 			}
 			return m, tea.Batch(hsCmd, initCmd)
+		} else if loadModel.finishedSuccessfully() && loadModel.editing {
+			m.chartEditorModel = initialChartEditorModel(loadModel, m.settings)
+			m.state = editChart
+			return m, m.chartEditorModel.Init()
 		} else if loadModel.finishedSuccessfully() {
+			if m.setlist.playing && m.setlist.track == nil {
+				m.setlist.track = loadModel.selectedTrack
+			}
 			playModel := createPlayModelFromLoadModel(loadModel, m.settings)
 			pmCmd := playModel.Init()
 			m.state = playSong
@@ -170,6 +219,19 @@ func (m mainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		if pm.playStats.failed || (pm.playStats.finished() && pm.songIsFinished()) {
 			m.statsScreenModel = initialStatsScreenModel(pm.chartInfo, pm.playStats, m.songRootPath, m.dbAccessor, m.speaker)
+			if m.careerSong != nil && !pm.playStats.failed {
+				err := m.dbAccessor.setCareerStars(m.settings.profile, m.careerScreenModel.career.name,
+					m.careerSong.chartHash, pm.playStats.starCount())
+				if err != nil {
+					log.Error("Failed to save career progress", "err", err)
+				}
+			}
+			if m.setlist.playing {
+				m.setlist.addResult(pm.chartInfo, pm.playStats)
+				if m.setlist.current+1 < len(m.setlist.songPaths) {
+					m.statsScreenModel.nextSongName = filepath.Base(m.setlist.songPaths[m.setlist.current+1])
+				}
+			}
 			m.state = statsScreen
 			pm.destroy()
 			return m, m.statsScreenModel.Init()
@@ -183,7 +245,20 @@ func (m mainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.statsScreenModel = statsModel.(statsScreenModel)
 		if m.statsScreenModel.shouldContinue {
 			m.statsScreenModel.destroy()
-			m.selectSongModel = initialSelectSongModel(m.songRootPath, m.dbAccessor, m.settings, m.speaker)
+			if m.careerSong != nil {
+				m.careerSong = nil
+				return m.showCareerScreen(m.statsScreenModel.chartInfo.fullFolderPath)
+			}
+			if m.setlist.playing {
+				if m.setlist.next() {
+					return m.loadSetlistSong()
+				}
+				m.setlistSummary = initialSetlistSummaryModel(m.setlist.results)
+				m.setlist.finish()
+				m.state = setlistSummary
+				return m, m.setlistSummary.Init()
+			}
+			m.selectSongModel = initialSelectSongModel(m.songRootPath, m.dbAccessor, m.settings, m.speaker, m.setlist)
 			m.state = chooseSong
 
 			ci := m.statsScreenModel.chartInfo
@@ -201,10 +276,88 @@ func (m mainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.Batch(initCmd, hsCmd)
 		}
 		return m, cmd
+	case setlistSummary:
+		summaryModel, cmd := m.setlistSummary.Update(msg)
+		m.setlistSummary = summaryModel.(setlistSummaryModel)
+		if m.setlistSummary.shouldContinue {
+			m.selectSongModel = initialSelectSongModel(m.songRootPath, m.dbAccessor, m.settings, m.speaker, m.setlist)
+			m.state = chooseSong
+
+			results := m.setlistSummary.results
+			initCmd := m.selectSongModel.Init()
+
+			// navigate to the last song of the setlist
+			var err error
+			var hsCmd tea.Cmd
+			m.selectSongModel, hsCmd, err = m.selectSongModel.highlightSongAbsolutePath(results[len(results)-1].chartInfo.fullFolderPath)
+			if err != nil {
+				panic(err)
+			}
+			return m, tea.Batch(initCmd, hsCmd)
+		}
+		return m, cmd
+	case careerScreen:
+		careerModel, cmd := m.careerScreenModel.Update(msg)
+		m.careerScreenModel = careerModel.(careerScreenModel)
+		if m.careerScreenModel.career != nil {
+			m.career = m.careerScreenModel.career
+		}
+		if m.careerScreenModel.backout {
+			m.selectSongModel = initialSelectSongModel(m.songRootPath, m.dbAccessor, m.settings, m.speaker, m.setlist)
+			m.state = chooseSong
+			return m, m.selectSongModel.Init()
+		} else if m.careerScreenModel.selectedSong != nil {
+			m.careerSong = m.careerScreenModel.selectedSong
+			loadModel := initialLoadModel(m.careerScreenModel.selectedSongPath, m.settings, m.speaker, m.dbAccessor)
+			m.state = loadSong
+			m.loadSongModel = loadModel
+			return m, loadModel.Init()
+		}
+		return m, cmd
+	case editChart:
+		editorModel, cmd := m.chartEditorModel.Update(msg)
+		m.chartEditorModel = editorModel.(chartEditorModel)
+		if m.chartEditorModel.exit {
+			m.chartEditorModel.destroy()
+			// the chart hashes change when the chart is saved, so the career is resolved again
+			m.career = nil
+			m.selectSongModel = initialSelectSongModel(m.songRootPath, m.dbAccessor, m.settings, m.speaker, m.setlist)
+			m.state = chooseSong
+
+			initCmd := m.selectSongModel.Init()
+
+			var err error
+			var hsCmd tea.Cmd
+			m.selectSongModel, hsCmd, err = m.selectSongModel.highlightSongAbsolutePath(m.chartEditorModel.chartInfo.fullFolderPath)
+			if err != nil {
+				panic(err)
+			}
+			return m, tea.Batch(initCmd, hsCmd)
+		}
+		return m, cmd
 	}
 	return m, nil
 }
 
+// highlightPath is the song that was just played, if there was one
+func (m mainModel) showCareerScreen(highlightPath string) (tea.Model, tea.Cmd) {
+	m.careerScreenModel = initialCareerScreenModel(m.songRootPath, m.selectSongModel.rootSongFolder, m.career,
+		m.dbAccessor, m.settings, highlightPath)
+	m.state = careerScreen
+	return m, m.careerScreenModel.Init()
+}
+
+// loads the setlist's current song, picking the same track as the first song when it has one
+func (m mainModel) loadSetlistSong() (tea.Model, tea.Cmd) {
+	loadModel := initialLoadModel(m.setlist.currentSongPath(), m.settings, m.speaker, m.dbAccessor)
+	if m.setlist.track != nil {
+		loadModel.autoSelectTrack = m.setlist.track.fullTrackName
+	}
+	m.state = loadSong
+	m.loadSongModel = loadModel
+	return m, loadModel.Init()
+}
+
 func splitFolderPath(folderPath string) []string {
 	var folderSeparatorMatcher = regexp.MustCompile(`[\\\/]`)
 	return folderSeparatorMatcher.Split(folderPath, -1)
@@ -222,6 +375,12 @@ func (m mainModel) View() string {
 		return m.playSongModel.View()
 	case statsScreen:
 		return m.statsScreenModel.View()
+	case setlistSummary:
+		return m.setlistSummary.View()
+	case careerScreen:
+		return m.careerScreenModel.View()
+	case editChart:
+		return m.chartEditorModel.View()
 	}
 	return "No view"
 }
@@ -253,6 +412,10 @@ func openLogFile() (*os.File, error) {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "lint" {
+		os.Exit(runLintCommand(os.Args[2:], os.Stdout))
+	}
+
 	logFile, err := openLogFile()
 	if err != nil {
 		panic(err)
@@ -262,8 +425,32 @@ func main() {
 	log.Info("Starting up")
 	defer logFile.Close()
 
-	p := tea.NewProgram(initialMainModel())
-	if _, err := p.Run(); err != nil {
+	settings := defaultSettings()
+	flag.BoolVar(&settings.doubleKick, "double-kick", false, "play the expert+ double kick notes in drum tracks")
+	flag.BoolVar(&settings.proDrums, "pro-drums", false, "score cymbals and toms separately on drums")
+	flag.StringVar(&settings.audioOutput, "audio", audioOutputAuto, "where the sound goes: "+strings.Join(audioOutputs, ", ")+". auto uses the audio device if there is one")
+	flag.StringVar(&settings.audioWavPath, "audio-file", "terminal-hero.wav", "the file that -audio wav writes to")
+	flag.BoolVar(&settings.hitSounds.enabled, "hit-sounds", false, "play a tick for each note hit")
+	flag.Float64Var(&settings.hitSounds.volume, "hit-sound-volume", defaultClickVolume, "the volume of the hit sounds, from 0 to 1")
+	flag.BoolVar(&settings.metronome.enabled, "metronome", false, "play a click on each beat of the song")
+	flag.Float64Var(&settings.metronome.volume, "metronome-volume", defaultClickVolume, "the volume of the metronome, from 0 to 1")
+	flag.BoolVar(&settings.countIn.enabled, "count-in", false, "play a measure of clicks before the first note and after unpausing")
+	flag.Float64Var(&settings.countIn.volume, "count-in-volume", defaultClickVolume, "the volume of the count-in, from 0 to 1")
+	flag.StringVar(&settings.profile, "profile", "default", "whose career progress is saved")
+	flag.StringVar(&settings.careerFilePath, "career", "", "the career file, which lists songs in tiers. defaults to career.json in the game data folder")
+	flag.Parse()
+	if !isValidAudioOutput(settings.audioOutput) {
+		fmt.Printf("unknown audio output %q, expected one of %s\n", settings.audioOutput, strings.Join(audioOutputs, ", "))
+		os.Exit(2)
+	}
+
+	model := initialMainModel(settings)
+	p := tea.NewProgram(model)
+	_, err = p.Run()
+	if closeErr := model.speaker.close(); closeErr != nil {
+		log.Error("failed to close audio output", "err", closeErr)
+	}
+	if err != nil {
 		fmt.Printf("error: %v", err)
 		os.Exit(1)
 	}
@@ -9,7 +9,7 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
-var gNoteStyles [5]lipgloss.Style = [5]lipgloss.Style{
+var gNoteStyles []lipgloss.Style = []lipgloss.Style{
 	lipgloss.NewStyle().Foreground(lipgloss.Color("#25b12b")),
 	lipgloss.NewStyle().Foreground(lipgloss.Color("#b4242d")),
 	lipgloss.NewStyle().Foreground(lipgloss.Color("#f6fa41")),
@@ -17,7 +17,7 @@ var gNoteStyles [5]lipgloss.Style = [5]lipgloss.Style{
 	lipgloss.NewStyle().Foreground(lipgloss.Color("#e68226")),
 }
 
-var gpNoteStyles [5]*lipgloss.Style = [5]*lipgloss.Style{
+var gpNoteStyles []*lipgloss.Style = []*lipgloss.Style{
 	&gNoteStyles[0],
 	&gNoteStyles[1],
 	&gNoteStyles[2],
@@ -25,10 +25,20 @@ var gpNoteStyles [5]*lipgloss.Style = [5]*lipgloss.Style{
 	&gNoteStyles[4],
 }
 
+var gBlackFretStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#8a8a8a")).Bold(true)
+var gWhiteFretStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#f5f5f5")).Bold(true)
+
+// black and white frets alternate, see sixFretLaneForNoteType
+var gpSixFretNoteStyles []*lipgloss.Style = []*lipgloss.Style{
+	&gBlackFretStyle, &gWhiteFretStyle,
+	&gBlackFretStyle, &gWhiteFretStyle,
+	&gBlackFretStyle, &gWhiteFretStyle,
+}
+
 var gpOpenNoteStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#90918e"))
 
-var gpSimpleNoteStyles [5]*lipgloss.Style = [5]*lipgloss.Style{
-	nil, nil, nil, nil, nil,
+var gpSimpleNoteStyles []*lipgloss.Style = []*lipgloss.Style{
+	nil, nil, nil, nil, nil, nil,
 }
 
 var multiplierStyles [4]lipgloss.Style = [4]lipgloss.Style{
@@ -50,6 +60,16 @@ var rockMeterBorderStyle = lipgloss.NewStyle().
 
 var gOverhitStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#ffffff"))
 
+var gBeatLineStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#3a3a3a"))
+var gMeasureLineStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#6c6c6c"))
+
+// indexed by beatLineKind
+var gpBeatLineStyles [3]*lipgloss.Style = [3]*lipgloss.Style{nil, &gBeatLineStyle, &gMeasureLineStyle}
+var gpSimpleBeatLineStyles [3]*lipgloss.Style = [3]*lipgloss.Style{nil, nil, nil}
+
+// the characters that fill the empty parts of a line, indexed by beatLineKind
+var beatLineFills [3]string = [3]string{" ", "─", "═"}
+
 func writeStyledString(r *strings.Builder, style *lipgloss.Style, str string) {
 	strToWrite := str
 	if style != nil {
@@ -58,7 +78,27 @@ func writeStyledString(r *strings.Builder, style *lipgloss.Style, str string) {
 	r.WriteString(strToWrite)
 }
 
-func (m playSongModel) CreateFretboardView(r *strings.Builder, noteStyles [5]*lipgloss.Style, overhitStyle *lipgloss.Style, openNoteStyle *lipgloss.Style) {
+// the styles for each lane of the track
+func (m playSongModel) noteStyles() []*lipgloss.Style {
+	if m.laneCount == sixFretLaneCount {
+		return gpSixFretNoteStyles
+	}
+	return gpNoteStyles
+}
+
+// the text for a note in the lane. black frets are square
+func (m playSongModel) noteText(lane int, cymbal bool) string {
+	label := laneLabel(m.laneCount, lane)
+	if cymbal {
+		return "/" + label + "\\"
+	}
+	if isBlackFretLane(m.laneCount, lane) {
+		return "[" + label + "]"
+	}
+	return "(" + label + ")"
+}
+
+func (m playSongModel) CreateFretboardView(r *strings.Builder, noteStyles []*lipgloss.Style, overhitStyle *lipgloss.Style, openNoteStyle *lipgloss.Style, beatLineStyles [3]*lipgloss.Style) {
 	strumLineIndex := m.getStrumLineIndex()
 
 	for i, line := range m.viewModel.NoteLine {
@@ -69,16 +109,17 @@ func (m playSongModel) CreateFretboardView(r *strings.Builder, noteStyles [5]*li
 				Foreground(lipgloss.Color("#e68226")).Underline(true).Padding(0, 0, 0, 2)
 			r.WriteString(pausedStyle.Render("PAUSED (ESC/ENTER)"))
 		} else {
+			fill, fillStyle := beatLineFills[line.BeatLine], beatLineStyles[line.BeatLine]
 			for noteType, isNote := range line.NoteColors {
 				if i == strumLineIndex {
 					r.WriteRune('-')
 				} else {
-					r.WriteRune(' ')
+					writeStyledString(r, fillStyle, fill)
 				}
 
 				noteStyle := noteStyles[noteType]
 				if isNote {
-					writeStyledString(r, noteStyle, "("+(strconv.Itoa(noteType+1))+")")
+					writeStyledString(r, noteStyle, m.noteText(noteType, line.Cymbals[noteType]))
 				} else {
 					if line.OpenNote {
 						writeStyledString(r, openNoteStyle, "---")
@@ -93,9 +134,11 @@ func (m playSongModel) CreateFretboardView(r *strings.Builder, noteStyles [5]*li
 					} else {
 						isHeldNote := line.HeldNotes[noteType]
 						if isHeldNote && i < strumLineIndex {
-							writeStyledString(r, noteStyle, " | ")
+							writeStyledString(r, fillStyle, fill)
+							writeStyledString(r, noteStyle, "|")
+							writeStyledString(r, fillStyle, fill)
 						} else {
-							r.WriteString("   ")
+							writeStyledString(r, fillStyle, strings.Repeat(fill, 3))
 						}
 					}
 				}
@@ -103,7 +146,7 @@ func (m playSongModel) CreateFretboardView(r *strings.Builder, noteStyles [5]*li
 				if i == strumLineIndex {
 					r.WriteRune('-')
 				} else {
-					r.WriteRune(' ')
+					writeStyledString(r, fillStyle, fill)
 				}
 			}
 		}
@@ -116,14 +159,14 @@ func (m playSongModel) CreateFretboardView(r *strings.Builder, noteStyles [5]*li
 
 func (m playSongModel) SimpleView() string {
 	r := strings.Builder{}
-	m.CreateFretboardView(&r, gpSimpleNoteStyles, nil, nil)
+	m.CreateFretboardView(&r, gpSimpleNoteStyles, nil, nil, gpSimpleBeatLineStyles)
 	r.WriteString("\nPress 0 to exit simple mode")
 	return r.String()
 }
 
 func (m playSongModel) ComplexView() string {
 	r := strings.Builder{}
-	m.CreateFretboardView(&r, gpNoteStyles, &gOverhitStyle, &gpOpenNoteStyle)
+	m.CreateFretboardView(&r, m.noteStyles(), &gOverhitStyle, &gpOpenNoteStyle, gpBeatLineStyles)
 
 	scoreAndMultiplier := strings.Builder{}
 
@@ -150,7 +193,12 @@ func (m playSongModel) ComplexView() string {
 	rockMeter.WriteString(rockArt + "\n")
 	rockMeter.WriteString(prog.ViewAs(m.playStats.rockMeter))
 
-	return lipgloss.JoinHorizontal(0.8, scoreAndMultiplierStyle.Render(scoreAndMultiplier.String()),
+	leftColumn := scoreAndMultiplierStyle.Render(scoreAndMultiplier.String())
+	if len(m.lyrics) > 0 {
+		leftColumn = lipgloss.JoinVertical(lipgloss.Left, leftColumn, lyricsView(m.lyrics, m.strumLineTimeMs()))
+	}
+
+	return lipgloss.JoinHorizontal(0.8, leftColumn,
 		"        ", r.String(), "        ",
 		rockMeterBorderStyle.Foreground(lipgloss.Color("#"+rockMeterColorMax.Hex())).
 			BorderForeground(lipgloss.Color("#"+rockMeterColorMax.Hex())).Render(rockMeter.String()))
@@ -15,6 +15,8 @@ type playSongModel struct {
 	chart         *Chart
 	chartInfo     chartInfo
 	realTimeNotes []playableNote // notes that have real timestamps (in milliseconds)
+	beatLines     []beatLineTime // measure and beat lines drawn on the highway
+	lyrics        []lyricPhrase
 
 	startTime     time.Time // datetime that the song started
 	currentTimeMs int       // current time position within the chart for notes that are now appearing
@@ -34,8 +36,15 @@ type playSongModel struct {
 	paused         bool
 	lastPausedTime time.Time
 	totalPauseTime time.Duration
+	countingIn     bool      // still paused while a measure of clicks plays after unpausing
+	countInEndTime time.Time // when the song carries on after the count-in
 
 	songSoundCtrl playableSound[*beep.Ctrl]
+	audioClock    audioClock // the song time once the music has started
+	preRollMs     int        // how long the count-in plays before the song starts
+	hitSound      [][2]float64
+
+	laneCount int // 5, or 6 for six-fret guitar
 }
 
 const (
@@ -46,19 +55,25 @@ const (
 	ncOrange
 )
 
-type NoteColors [5]bool
+// one per lane
+type NoteColors []bool
+
+// the keys for hitting cymbals on drums, in the same order as the 1-5 keys for toms
+const cymbalKeys = "qwert"
 
 type NoteLine struct {
-	NoteColors [5]bool
-	HeldNotes  [5]bool
+	NoteColors NoteColors
+	HeldNotes  []bool
+	Cymbals    []bool
 	OpenNote   bool
+	BeatLine   beatLineKind
 	// debug info
 	DisplayTimeMs int
 }
 
 type viewModel struct {
 	NoteLine      []NoteLine
-	noteStates    [5]currentNoteState
+	noteStates    []currentNoteState // one per lane
 	openNoteState currentNoteState
 }
 
@@ -85,14 +100,22 @@ func mixSounds(sounds ...playableSound[beep.Streamer]) playableSound[beep.Stream
 	}
 
 	streams := make([]beep.Streamer, 0)
+	format := sounds[0].format
 	for _, sound := range sounds {
-		if sound.format.SampleRate != sounds[0].format.SampleRate {
+		if sound.soundStream == nil {
+			// missing stems have no format
+			continue
+		}
+		if len(streams) == 0 {
+			format = sound.format
+		}
+		if sound.format.SampleRate != format.SampleRate {
 			log.Error("format mismatch in mixSounds")
-		} else if sound.soundStream != nil {
+		} else {
 			streams = append(streams, sound.soundStream)
 		}
 	}
-	return playableSound[beep.Streamer]{beep.Mix(streams...), sounds[0].format}
+	return playableSound[beep.Streamer]{beep.Mix(streams...), format}
 }
 
 func convToStandardSound[T beep.Streamer](s playableSound[T]) playableSound[beep.Streamer] {
@@ -107,51 +130,81 @@ func createPlayModelFromLoadModel(lm loadSongModel, stngs *settings) playSongMod
 	model.songSounds = lm.songSounds.songSounds
 	model.soundEffects = lm.soundEffects.soundEffects
 
-	model.currentInstrumentVolumeControl().Volume = 0.5
+	if volumeControl := model.currentInstrumentVolumeControl(); volumeControl != nil {
+		volumeControl.Volume = 0.5
+	}
 
-	for _, instrum := range []string{instrumentGuitar, instrumentBass, instrumentDrums} {
-		if model.chartInfo.track.instrument != instrum {
-			stream := model.songSounds.getSongSoundForInstrument(instrum).soundStream
-			if stream != nil {
-				log.Infof("Decreasing volume for %s", instrum)
-				stream.Volume = -0.3
-			}
+	for _, instrum := range []string{instrumentGuitar, instrumentBass, instrumentRhythm, instrumentDrums, instrumentKeys} {
+		stream := model.songSounds.getSongSoundForInstrument(instrum).soundStream
+		if stream != nil && stream != model.currentInstrumentVolumeControl() {
+			log.Infof("Decreasing volume for %s", instrum)
+			stream.Volume = -0.3
 		}
 	}
 
 	// the sounds should all be resampled by this point
 	mixed := mixSounds(convToStandardSound(model.songSounds.song), convToStandardSound(model.songSounds.guitar),
-		convToStandardSound(model.songSounds.bass), convToStandardSound(model.songSounds.drums))
+		convToStandardSound(model.songSounds.bass), convToStandardSound(model.songSounds.drums),
+		convToStandardSound(model.songSounds.keys), convToStandardSound(model.songSounds.rhythm))
+
+	songStream := mixed.soundStream
+	if clicks := model.songClicks(); len(clicks) > 0 {
+		// clicks before the start of the song are played over silence
+		for _, click := range clicks {
+			model.preRollMs = max(model.preRollMs, -click.timeMs)
+		}
+		if model.preRollMs > 0 {
+			preRoll := beep.Silence(mixed.format.SampleRate.N(time.Duration(model.preRollMs) * time.Millisecond))
+			songStream = beep.Seq(preRoll, songStream)
+		}
+		songStream = beep.Mix(songStream, newClickTrack(mixed.format, -model.preRollMs, clicks))
+	}
+	if stngs.hitSounds.enabled {
+		model.hitSound = newHitSound(mixed.format, stngs.hitSounds.volume)
+	}
 
-	model.songSoundCtrl = playableSound[*beep.Ctrl]{&beep.Ctrl{Streamer: mixed.soundStream}, mixed.format}
+	position := &positionStreamer{streamer: songStream}
+	model.songSoundCtrl = playableSound[*beep.Ctrl]{&beep.Ctrl{Streamer: position}, mixed.format}
+	model.audioClock = newAudioClock(position, mixed.format)
 
 	model.startTime = time.Now()
+	lineTimeMs := int(model.lineTime / time.Millisecond)
+	if leadInMs := lineTimeMs * model.getStrumLineIndex(); model.preRollMs > leadInMs {
+		// the highway starts early enough for the count-in
+		model.startTime = model.startTime.Add(time.Duration(model.preRollMs-leadInMs) * time.Millisecond)
+	}
 	model.speaker = lm.speaker
 
 	return model
 }
 
+// the metronome and the count-in before the first note
+func (m playSongModel) songClicks() []scheduledClick {
+	clicks := make([]scheduledClick, 0)
+	if m.settings.metronome.enabled {
+		clicks = append(clicks, metronomeClicks(m.beatLines, m.settings.metronome.volume)...)
+	}
+	if m.settings.countIn.enabled && len(m.realTimeNotes) > 0 {
+		clicks = append(clicks, countInClicks(m.beatLines, m.realTimeNotes[0].TimeStamp, m.settings.countIn.volume)...)
+	}
+	return clicks
+}
+
 func (m playSongModel) getStrumLineIndex() int {
 	return m.settings.fretBoardHeight - 5
 }
 
 func createModelFromChart(chart *Chart, trackName trackName, stngs *settings) playSongModel {
 	realNotes := getNotesWithRealTimestamps(chart, trackName.fullTrackName)
-	playableNotes := make([]playableNote, len(realNotes))
-	for i, note := range realNotes {
-		if trackName.instrument == instrumentDrums {
-			if note.RawNoteType == 0 {
-				// for drums, 0 is the open note
-				playableNotes[i] = playableNote{false, openNoteColorIndex, true, note}
-			} else {
-				playableNotes[i] = playableNote{false, note.RawNoteType - 1, false, note}
-			}
-		} else {
-			// for guitar, 7 is the open note
-			if note.RawNoteType == 7 {
-				playableNotes[i] = playableNote{false, openNoteColorIndex, true, note}
-			} else {
-				playableNotes[i] = playableNote{false, note.RawNoteType, false, note}
+	var playableNotes []playableNote
+	if trackName.instrument == instrumentDrums {
+		playableNotes = createDrumPlayableNotes(chart.Tracks[trackName.fullTrackName], realNotes, stngs.doubleKick)
+	} else {
+		playableNotes = make([]playableNote, 0, len(realNotes))
+		for _, note := range realNotes {
+			lane, isOpenNote, ok := laneForNoteType(trackName, note.RawNoteType)
+			if ok {
+				playableNotes = append(playableNotes, playableNote{fretIndex: lane, isOpenNote: isOpenNote, Note: note})
 			}
 		}
 	}
@@ -163,12 +216,23 @@ func createModelFromChart(chart *Chart, trackName trackName, stngs *settings) pl
 		lineTime = stngs.drumLineTime
 	}
 
+	endTick := 0
+	for _, note := range chart.Tracks[trackName.fullTrackName] {
+		if note.TimeStamp+int(note.ExtraData) > endTick {
+			endTick = note.TimeStamp + int(note.ExtraData)
+		}
+	}
+
 	return playSongModel{
 		chart:         chart,
 		realTimeNotes: playableNotes,
+		beatLines:     getBeatLineTimes(chart, endTick),
+		lyrics:        getLyricPhrases(chart, NewTempoMap(chart)),
 		startTime:     startTime,
 		settings:      stngs,
 		lineTime:      lineTime,
+		laneCount:     laneCountForTrack(trackName),
+		viewModel:     viewModel{noteStates: make([]currentNoteState, laneCountForTrack(trackName))},
 		playStats: playStats{
 			lastPlayedNoteIndex: -1,
 			totalNotes:          countNotes(playableNotes),
@@ -205,8 +269,9 @@ func (m playSongModel) CreateCurrentNoteChart() viewModel {
 	isDrums := m.isDrums()
 
 	for i := 0; i < m.settings.fretBoardHeight; i++ {
-		var noteColors NoteColors = NoteColors{}
-		var heldNotes [5]bool = [5]bool{}
+		noteColors := make(NoteColors, m.laneCount)
+		heldNotes := make([]bool, m.laneCount)
+		cymbals := make([]bool, m.laneCount)
 		openNote := false
 		for j := latestNotPrintedNoteIndex; j >= 0; j-- {
 			note := m.realTimeNotes[j]
@@ -217,6 +282,7 @@ func (m playSongModel) CreateCurrentNoteChart() viewModel {
 						openNote = true
 					} else {
 						noteColors[note.fretIndex] = true
+						cymbals[note.fretIndex] = note.cymbal
 					}
 				}
 
@@ -225,8 +291,8 @@ func (m playSongModel) CreateCurrentNoteChart() viewModel {
 				if !isDrums {
 					chord := getPreviousNoteOrChord(m.realTimeNotes, j)
 					for _, chordNote := range chord {
-						if chordNote.TimeStamp+int(chordNote.ExtraData-100) >= displayTimeMs {
-							heldNotes[note.fretIndex] = true
+						if !chordNote.isOpenNote && chordNote.TimeStamp+int(chordNote.ExtraData-100) >= displayTimeMs {
+							heldNotes[chordNote.fretIndex] = true
 						}
 					}
 				}
@@ -236,7 +302,8 @@ func (m playSongModel) CreateCurrentNoteChart() viewModel {
 			}
 		}
 
-		result[i] = NoteLine{noteColors, heldNotes, openNote, displayTimeMs}
+		beatLine := beatLineBetween(m.beatLines, displayTimeMs, displayTimeMs+lineTimeMs)
+		result[i] = NoteLine{noteColors, heldNotes, cymbals, openNote, beatLine, displayTimeMs}
 
 		displayTimeMs -= lineTimeMs
 	}
@@ -273,6 +340,26 @@ func (m playSongModel) ProcessNoNotePlayed(strumTimeMs int) playSongModel {
 
 // should be called when a note is played (ex: keyboard button pressed)
 func (m playSongModel) PlayNote(colorIndex int, strumTimeMs int) playSongModel {
+	return m.playHit(colorIndex, false, strumTimeMs)
+}
+
+func (m playSongModel) isProDrums() bool {
+	return m.settings.proDrums && m.isDrums()
+}
+
+// with pro drums, cymbal and tom hits only match their own kind of note
+func (m playSongModel) hitMatchesNote(note playableNote, colorIndex int, cymbal bool) bool {
+	if note.fretIndex != colorIndex {
+		return false
+	}
+	return !m.isProDrums() || note.isOpenNote || note.cymbal == cymbal
+}
+
+// plays a note of the color. cymbal is only used for pro drums
+func (m playSongModel) playHit(colorIndex int, cymbal bool, strumTimeMs int) playSongModel {
+	// the note states are changed below, so they can't be shared with the previous model
+	m.viewModel.noteStates = append([]currentNoteState(nil), m.viewModel.noteStates...)
+
 	strumToleranceMs := int(m.settings.strumTolerance / time.Millisecond)
 	minTime := strumTimeMs - strumToleranceMs
 	maxTime := strumTimeMs + strumToleranceMs
@@ -293,12 +380,11 @@ func (m playSongModel) PlayNote(colorIndex int, strumTimeMs int) playSongModel {
 			break
 		}
 
-		var vmNoteState *currentNoteState
-		if colorIndex == openNoteColorIndex {
-			vmNoteState = &m.viewModel.openNoteState
-		} else {
-			vmNoteState = &m.viewModel.noteStates[colorIndex]
+		if colorIndex != openNoteColorIndex && (colorIndex < 0 || colorIndex >= len(m.viewModel.noteStates)) {
+			// not a lane of this track
+			break
 		}
+		vmNoteState := m.viewModel.noteStatePtr(colorIndex)
 
 		if note.TimeStamp > maxTime {
 			// no more notes to check
@@ -326,7 +412,7 @@ func (m playSongModel) PlayNote(colorIndex int, strumTimeMs int) playSongModel {
 		if len(chord) == 1 {
 			// gotta be careful with chords when looping forward too
 
-			if note.fretIndex == colorIndex {
+			if m.hitMatchesNote(note, colorIndex, cymbal) {
 				// handle correct single note played
 				m.realTimeNotes[i].played = true
 				m.playStats.hitNote(1)
@@ -335,6 +421,7 @@ func (m playSongModel) PlayNote(colorIndex int, strumTimeMs int) playSongModel {
 				vmNoteState.lastPlayedMs = strumTimeMs
 
 				m.unmuteCurrentInstrument()
+				m.playHitSound()
 				m.playStats.lastPlayedNoteIndex = i
 				break
 			}
@@ -346,7 +433,7 @@ func (m playSongModel) PlayNote(colorIndex int, strumTimeMs int) playSongModel {
 			overhitChord := false
 			foundMatchingChordNote := false
 			for _, chordNote := range chord {
-				if chordNote.fretIndex == colorIndex {
+				if m.hitMatchesNote(chordNote, colorIndex, cymbal) {
 					foundMatchingChordNote = true
 					if vmNoteState.lastCorrectlyPlayedChordNoteTimeMs == chordNote.TimeStamp {
 						// already played!!
@@ -396,6 +483,7 @@ func (m playSongModel) PlayNote(colorIndex int, strumTimeMs int) playSongModel {
 					m.realTimeNotes[i+ci].played = true
 				}
 				m.unmuteCurrentInstrument()
+				m.playHitSound()
 				m.playStats.lastPlayedNoteIndex += len(chord)
 				break
 			} else {
@@ -440,16 +528,31 @@ func (m playSongModel) currentInstrumentVolumeControl() *effects.Volume {
 
 func (ss songSounds) getSongSoundForInstrument(instrument string) playableSound[*effects.Volume] {
 	switch instrument {
-	case instrumentGuitar:
+	case instrumentGuitar, instrumentGHLGuitar, instrumentCoopGuitar:
 		return ss.guitar
-	case instrumentBass:
+	case instrumentBass, instrumentGHLBass:
+		// older songs have the bass in rhythm.ogg
+		if ss.bass.soundStream == nil {
+			return ss.rhythm
+		}
 		return ss.bass
+	case instrumentRhythm:
+		return ss.rhythm
 	case instrumentDrums:
 		return ss.drums
+	case instrumentKeys:
+		return ss.keys
 	}
 	return playableSound[*effects.Volume]{}
 }
 
+func (m playSongModel) playHitSound() {
+	if m.hitSound == nil {
+		return
+	}
+	m.speaker.play(&samplesStreamer{samples: m.hitSound}, m.songSoundCtrl.format)
+}
+
 func (m playSongModel) setGuitarSilent(silent bool) {
 	volControl := m.currentInstrumentVolumeControl()
 	if volControl == nil {
@@ -470,6 +573,7 @@ func (m playSongModel) destroy() {
 	if m.speaker != nil {
 		m.speaker.clear()
 	}
+	m.songSounds.close()
 }
 
 func (m playSongModel) isPauseMsg(msg tea.Msg) bool {
@@ -483,17 +587,20 @@ func (m playSongModel) isPauseMsg(msg tea.Msg) bool {
 
 func (m playSongModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if m.paused {
-		switch msg.(type) {
+		switch msg := msg.(type) {
 		case tickMsg:
+			if m.countingIn && !time.Time(msg).Before(m.countInEndTime) {
+				m = m.resume()
+			}
 			return m, timerCmd(m.lineTime)
 		}
 
-		if m.isPauseMsg(msg) {
-			m.totalPauseTime += time.Since(m.lastPausedTime)
-			m.paused = false
-			speaker.Lock()
-			m.songSoundCtrl.soundStream.Paused = false
-			speaker.Unlock()
+		if m.isPauseMsg(msg) && !m.countingIn {
+			if m.settings.countIn.enabled && m.startedMusic {
+				m = m.startCountIn()
+			} else {
+				m = m.resume()
+			}
 		}
 
 		return m, nil
@@ -509,21 +616,27 @@ func (m playSongModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tickMsg:
-		m.currentTimeMs += int(m.lineTime / time.Millisecond)
-		currentDateTime := time.Time(tickMsg(msg))
-		elapsedTimeSinceStart := currentDateTime.Sub(m.startTime) - m.totalPauseTime
-		sleepTime := time.Duration(m.currentTimeMs)*time.Millisecond - elapsedTimeSinceStart
+		now := time.Time(tickMsg(msg))
+		if m.isAudioClockRunning() {
+			m.audioClock = m.audioClock.update(now)
+		}
+		strumTimeMs := m.strumTimeMsAt(now)
 
-		m = m.ProcessNoNotePlayed(m.currentStrumTimeMs())
+		// the highway moves a whole line at a time, so wait until the next line
+		lineTimeMs := int(m.lineTime / time.Millisecond)
+		lines := floorDiv(strumTimeMs, lineTimeMs)
+		m.currentTimeMs = (lines + m.getStrumLineIndex()) * lineTimeMs
+		sleepTime := time.Duration((lines+1)*lineTimeMs-strumTimeMs) * time.Millisecond
+
+		m = m.ProcessNoNotePlayed(strumTimeMs)
 		m = m.UpdateViewModel()
 
-		if m.viewModel.NoteLine[m.getStrumLineIndex()-1].DisplayTimeMs == 0 {
-			if !m.startedMusic {
-				log.Info("Starting song music")
+		if !m.startedMusic && strumTimeMs >= -m.preRollMs {
+			log.Info("Starting song music")
 
-				m.speaker.play(m.songSoundCtrl.soundStream, m.songSoundCtrl.format)
-				m.startedMusic = true
-			}
+			m.speaker.play(m.songSoundCtrl.soundStream, m.songSoundCtrl.format)
+			m.audioClock = m.audioClock.start(now)
+			m.startedMusic = true
 		}
 
 		if m.playStats.failed {
@@ -537,11 +650,16 @@ func (m playSongModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if keyName == "space" || keyName == " " {
 			log.Info("keyName " + keyName)
 			m = m.playNoteNow(openNoteColorIndex)
-		} else if len(keyName) == 1 && ('1' <= keyName[0] && keyName[0] <= '5') {
-			noteIndex := int(keyName[0] - '1')
-
+		} else if noteIndex, ok := laneForKey(m.laneCount, keyName); ok {
 			m = m.playNoteNow(noteIndex)
 
+			if m.playStats.failed {
+				m.destroy()
+				return m, nil
+			}
+		} else if len(keyName) == 1 && m.isDrums() && strings.Contains(cymbalKeys, keyName) {
+			m = m.playHit(strings.Index(cymbalKeys, keyName), true, m.currentStrumTimeMs())
+
 			if m.playStats.failed {
 				m.destroy()
 				return m, nil
@@ -557,6 +675,36 @@ func (m playSongModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+func (m playSongModel) resume() playSongModel {
+	m.totalPauseTime += time.Since(m.lastPausedTime)
+	m.audioClock = m.audioClock.resume(time.Now())
+	m.paused = false
+	m.countingIn = false
+	speaker.Lock()
+	m.songSoundCtrl.soundStream.Paused = false
+	speaker.Unlock()
+	return m
+}
+
+// plays a measure of clicks at the current tempo, then unpauses the song right after the last one
+func (m playSongModel) startCountIn() playSongModel {
+	beatMs, beats := beatsAt(m.beatLines, m.strumLineTimeMs())
+	countInDuration := time.Duration(beats*beatMs) * time.Millisecond
+
+	format := m.songSoundCtrl.format
+	clicks := newClickTrack(format, 0, measureOfClicks(0, beatMs, beats, m.settings.countIn.volume))
+	ctrl := m.songSoundCtrl.soundStream
+	m.speaker.play(beep.Seq(
+		beep.Take(format.SampleRate.N(countInDuration), clicks),
+		// runs while the speaker is locked
+		beep.Callback(func() { ctrl.Paused = false }),
+	), format)
+
+	m.countingIn = true
+	m.countInEndTime = time.Now().Add(countInDuration)
+	return m
+}
+
 func (m playSongModel) playLastHitNote(strumTimeMs int) playSongModel {
 	var lastPlayedNoteOrChord []playableNote
 	startIndex := m.playStats.lastPlayedNoteIndex
@@ -569,7 +717,7 @@ func (m playSongModel) playLastHitNote(strumTimeMs int) playSongModel {
 	}
 
 	for _, note := range lastPlayedNoteOrChord {
-		m = m.PlayNote(note.fretIndex, strumTimeMs)
+		m = m.playHit(note.fretIndex, note.cymbal, strumTimeMs)
 	}
 	return m
 }
@@ -582,13 +730,39 @@ func (m playSongModel) playNoteNow(noteIndex int) playSongModel {
 	return m.PlayNote(noteIndex, m.currentStrumTimeMs())
 }
 
+// the song time of the notes at the strum line, as of the last view update
+func (m playSongModel) strumLineTimeMs() int {
+	lineTimeMs := int(m.lineTime / time.Millisecond)
+	return m.currentTimeMs - lineTimeMs*m.getStrumLineIndex()
+}
+
 func (m playSongModel) currentStrumTimeMs() int {
+	return m.strumTimeMsAt(time.Now())
+}
+
+func (m playSongModel) isAudioClockRunning() bool {
+	return m.startedMusic && m.audioClock.position != nil
+}
+
+// the song time at the strum line. before the music starts, the highway scrolls by the wall clock
+func (m playSongModel) strumTimeMsAt(now time.Time) int {
+	if m.isAudioClockRunning() {
+		return int(m.audioClock.at(now)) - m.preRollMs
+	}
+
 	lineTimeMs := int(m.lineTime / time.Millisecond)
 	strumLineIndex := m.getStrumLineIndex()
-	currentDateTime := time.Now()
-	elapsedTimeSinceStart := currentDateTime.Sub(m.startTime) - m.totalPauseTime
-	strumTimeMs := int(elapsedTimeSinceStart/time.Millisecond) - (lineTimeMs * strumLineIndex)
-	return strumTimeMs
+	elapsedTimeSinceStart := now.Sub(m.startTime) - m.totalPauseTime
+	return int(elapsedTimeSinceStart/time.Millisecond) - (lineTimeMs * strumLineIndex)
+}
+
+// integer division that rounds down for negative numbers too
+func floorDiv(a int, b int) int {
+	result := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		result--
+	}
+	return result
 }
 
 func (m playSongModel) songIsFinished() bool {
@@ -4,6 +4,7 @@ type playableNote struct {
 	played     bool
 	fretIndex  int // the real index of the note along the fretboard, ignoring the open note (kick pedal)
 	isOpenNote bool
+	cymbal     bool // for pro drums, the note is a cymbal instead of a tom
 	Note
 }
 
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/charmbracelet/log"
+)
+
+const favoritesPlaylistName = "Favorites"
+
+// the hash of the song's chart, which is what scores and playlists are stored by
+func (fldr *songFolder) songChartHash() (string, error) {
+	if fldr.chartHash == "" {
+		ch, err := hashFileByPath(filepath.Join(fldr.path, "notes.chart"))
+		if err != nil {
+			return "", err
+		}
+		fldr.chartHash = ch
+	}
+	return fldr.chartHash, nil
+}
+
+func (fldr *songFolder) song() (song, error) {
+	ch, err := fldr.songChartHash()
+	if err != nil {
+		return song{}, err
+	}
+	rp, err := fldr.relativePath()
+	if err != nil {
+		return song{}, err
+	}
+	return song{ch, rp, fldr.name}, nil
+}
+
+// adds a virtual folder for each playlist to the top of the root folder. a song is looked
+// for where it was last seen, and if it isn't there anymore every chart is hashed to find it.
+// songs that aren't in the library anymore are left out
+func loadPlaylistFolders(root *songFolder, db grDbAccessor) error {
+	playlists, err := db.getPlaylists()
+	if err != nil {
+		return err
+	}
+
+	var songsByHash map[string]*songFolder
+	for _, p := range playlists {
+		for _, s := range p.Songs {
+			f := root.queryFolder(splitFolderPath(s.RelativePath))
+			if f != nil && f.isLeaf {
+				if ch, err := f.songChartHash(); err == nil && ch == s.ChartHash {
+					root.addToPlaylistFolder(p.Name, f)
+					continue
+				}
+			}
+
+			if songsByHash == nil {
+				songsByHash = hashAllSongs(root)
+			}
+			f = songsByHash[s.ChartHash]
+			if f == nil {
+				continue
+			}
+			root.addToPlaylistFolder(p.Name, f)
+			rp, err := f.relativePath()
+			if err == nil {
+				err = db.updatePlaylistSongPath(s.ChartHash, rp)
+			}
+			if err != nil {
+				log.Error("Failed to update where a playlist song is", "song", s.Name, "err", err)
+			}
+		}
+	}
+	return nil
+}
+
+func hashAllSongs(root *songFolder) map[string]*songFolder {
+	songsByHash := make(map[string]*songFolder)
+	var hashSongs func(fldr *songFolder)
+	hashSongs = func(fldr *songFolder) {
+		for _, f := range fldr.subFolders {
+			if f.playlistName != "" {
+				continue
+			}
+			if f.isLeaf {
+				ch, err := f.songChartHash()
+				if err == nil {
+					songsByHash[ch] = f
+				} else if !errors.Is(err, os.ErrNotExist) {
+					log.Error("Failed to hash chart", "path", f.path, "err", err)
+				}
+			}
+			hashSongs(f)
+		}
+	}
+	hashSongs(root)
+	return songsByHash
+}
+
+// the virtual folder of the playlist, or nil if the playlist has no songs
+func (root *songFolder) playlistFolder(name string) *songFolder {
+	for _, f := range root.subFolders {
+		if f.playlistName == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// adds the song to the end of the playlist's folder, which is created after the other playlists
+// if it doesn't exist. favorites are always first. returns the song's entry in the playlist
+func (root *songFolder) addToPlaylistFolder(name string, song *songFolder) *songFolder {
+	pf := root.playlistFolder(name)
+	if pf == nil {
+		pf = &songFolder{name: name, path: root.path, parent: root, subFolders: []*songFolder{},
+			context: root.context, playlistName: name}
+		insertAt := 0
+		for insertAt < len(root.subFolders) && root.subFolders[insertAt].playlistName != "" {
+			insertAt++
+		}
+		if name == favoritesPlaylistName {
+			insertAt = 0
+		}
+		root.subFolders = append(root.subFolders[:insertAt], append([]*songFolder{pf}, root.subFolders[insertAt:]...)...)
+	}
+
+	// the entry is a copy so that going back from it goes to the playlist
+	entry := *song
+	entry.parent = pf
+	pf.subFolders = append(pf.subFolders, &entry)
+	pf.songCount++
+	return &entry
+}
+
+// removes the song from the playlist's folder, and the folder from the root once it's empty
+func (root *songFolder) removeFromPlaylistFolder(name string, chartHash string) {
+	pf := root.playlistFolder(name)
+	if pf == nil {
+		return
+	}
+	for i, f := range pf.subFolders {
+		if f.chartHash == chartHash {
+			pf.subFolders = append(pf.subFolders[:i], pf.subFolders[i+1:]...)
+			pf.songCount--
+			break
+		}
+	}
+	if len(pf.subFolders) == 0 {
+		for i, f := range root.subFolders {
+			if f == pf {
+				root.subFolders = append(root.subFolders[:i], root.subFolders[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+func (root *songFolder) playlistContains(name string, chartHash string) bool {
+	pf := root.playlistFolder(name)
+	if pf == nil {
+		return false
+	}
+	for _, f := range pf.subFolders {
+		if f.chartHash == chartHash {
+			return true
+		}
+	}
+	return false
+}
+
+// the names of the playlists, for showing which ones songs can be added to
+func (root *songFolder) playlistNames() []string {
+	names := []string{}
+	for _, f := range root.subFolders {
+		if f.playlistName != "" {
+			names = append(names, f.playlistName)
+		}
+	}
+	return names
+}
+
+func (pf *songFolder) swapPlaylistEntries(entry1 *songFolder, entry2 *songFolder) {
+	i1, i2 := -1, -1
+	for i, f := range pf.subFolders {
+		if f == entry1 {
+			i1 = i
+		} else if f == entry2 {
+			i2 = i
+		}
+	}
+	if i1 >= 0 && i2 >= 0 {
+		pf.subFolders[i1], pf.subFolders[i2] = pf.subFolders[i2], pf.subFolders[i1]
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// a song folder with a chart that's only the text, so that each song has its own hash
+func writeTestSongFolder(t *testing.T, folderPath string, chartText string) string {
+	err := os.MkdirAll(folderPath, 0777)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chartPath := filepath.Join(folderPath, "notes.chart")
+	err = os.WriteFile(chartPath, []byte(chartText), 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch, err := hashFileByPath(chartPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ch
+}
+
+func TestLoadPlaylistFolders(t *testing.T) {
+	rootPath := t.TempDir()
+	oneHash := writeTestSongFolder(t, filepath.Join(rootPath, "GH3", "One"), "one")
+	movedHash := writeTestSongFolder(t, filepath.Join(rootPath, "RB", "Moved"), "moved")
+
+	db, err := openAndMigrateTestDb()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.destroy(t)
+
+	for _, s := range []song{
+		{oneHash, filepath.Join("GH3", "One"), "One"},
+		{"deleted", filepath.Join("GH3", "Deleted"), "Deleted"},
+		{movedHash, filepath.Join("GH3", "Moved"), "Moved"},
+	} {
+		err = db.addToPlaylist("Road Trip", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = db.addToPlaylist(favoritesPlaylistName, song{oneHash, filepath.Join("GH3", "One"), "One"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := loadSongFolder(rootPath)
+	err = loadPlaylistFolders(root, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// favorites first, then the other playlists, then the library
+	names := songFolderNames(root.subFolders)
+	if len(names) != 4 || names[0] != favoritesPlaylistName || names[1] != "Road Trip" {
+		t.Fatal("Expected Favorites and Road Trip before the game folders, got", names)
+	}
+
+	roadTrip := root.playlistFolder("Road Trip")
+	names = songFolderNames(roadTrip.subFolders)
+	if len(names) != 2 || names[0] != "One" || names[1] != "Moved" {
+		t.Error("Expected the moved song to be found and the deleted one left out, got", names)
+	}
+	if roadTrip.subFolders[0].parent != roadTrip {
+		t.Error("Expected going back from a playlist song to go to the playlist")
+	}
+	if root.getSubfolder(favoritesPlaylistName) != nil {
+		t.Error("Expected playlists to not be found as song folders")
+	}
+	if results := root.search("one"); len(results) != 1 {
+		t.Error("Expected songs in playlists to only be found once, got", songFolderNames(results))
+	}
+
+	playlists, err := db.getPlaylists()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved := playlists[0].Songs[2]; moved.RelativePath != filepath.Join("RB", "Moved") {
+		t.Error("Expected the moved song's path to be updated, got", moved.RelativePath)
+	}
+
+	root.removeFromPlaylistFolder(favoritesPlaylistName, oneHash)
+	if root.playlistFolder(favoritesPlaylistName) != nil {
+		t.Error("Expected the empty favorites folder to be removed")
+	}
+}
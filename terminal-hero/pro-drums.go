@@ -0,0 +1,75 @@
+package main
+
+// raw note types in drum tracks
+const (
+	drumsDoubleKickNoteType = 32 // expert+ kick, only played when double kick is on
+
+	// markers at the same tick as a yellow, blue or green note that make it a cymbal
+	drumsYellowCymbalMarker = 66
+	drumsBlueCymbalMarker   = 67
+	drumsGreenCymbalMarker  = 68
+)
+
+// the raw note type of the pad that the cymbal marker applies to
+func cymbalMarkerPad(rawNoteType int) (int, bool) {
+	switch rawNoteType {
+	case drumsYellowCymbalMarker, drumsBlueCymbalMarker, drumsGreenCymbalMarker:
+		return rawNoteType - 64, true
+	}
+	return 0, false
+}
+
+type drumPad struct {
+	tick        int
+	rawNoteType int
+}
+
+// converts drum notes to playable notes. rawNotes and realNotes must be the same notes,
+// with tick and real timestamps. cymbal markers are applied to the pads they mark and
+// notes that can't be played (including double kicks when they're off) are left out
+func createDrumPlayableNotes(rawNotes []Note, realNotes []Note, doubleKick bool) []playableNote {
+	cymbals := make(map[drumPad]bool)
+	for _, note := range rawNotes {
+		if pad, ok := cymbalMarkerPad(note.RawNoteType); ok {
+			cymbals[drumPad{note.TimeStamp, pad}] = true
+		}
+	}
+
+	result := make([]playableNote, 0, len(realNotes))
+	for i, note := range realNotes {
+		rawNote := rawNotes[i]
+		switch {
+		case rawNote.RawNoteType == drumsKickNoteType,
+			rawNote.RawNoteType == drumsDoubleKickNoteType && doubleKick:
+			// the kick pedal is the open note
+			result = append(result, playableNote{fretIndex: openNoteColorIndex, isOpenNote: true, Note: note})
+		case rawNote.RawNoteType >= 1 && rawNote.RawNoteType <= 5:
+			result = append(result, playableNote{
+				fretIndex: rawNote.RawNoteType - 1,
+				cymbal:    cymbals[drumPad{rawNote.TimeStamp, rawNote.RawNoteType}],
+				Note:      note,
+			})
+		}
+	}
+	return removeDuplicateKicks(result)
+}
+
+// double kicks can be at the same tick as a normal kick, which would need to be hit twice at once
+func removeDuplicateKicks(notes []playableNote) []playableNote {
+	result := make([]playableNote, 0, len(notes))
+	for _, note := range notes {
+		if note.isOpenNote {
+			duplicate := false
+			for i := len(result) - 1; i >= 0 && result[i].TimeStamp == note.TimeStamp; i-- {
+				if result[i].isOpenNote {
+					duplicate = true
+				}
+			}
+			if duplicate {
+				continue
+			}
+		}
+		result = append(result, note)
+	}
+	return result
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const proDrumsChart = `[Song]
+{
+	Resolution = 192
+}
+[SyncTrack]
+{
+	0 = B 120000
+}
+[ExpertDrums]
+{
+	0 = N 0 0
+	0 = N 2 0
+	0 = N 66 0
+	192 = N 32 0
+	192 = N 3 0
+	384 = N 0 0
+	384 = N 32 0
+	384 = N 4 0
+	384 = N 68 0
+}
+`
+
+func TestCreateDrumPlayableNotes_CymbalsAndDoubleKick(t *testing.T) {
+	chart := parseTestChart(t, proDrumsChart)
+	rawNotes := chart.Tracks["ExpertDrums"]
+	realNotes := getNotesWithRealTimestamps(chart, "ExpertDrums")
+
+	type drumNote struct {
+		timeMs    int
+		fretIndex int
+		cymbal    bool
+	}
+	toDrumNotes := func(notes []playableNote) []drumNote {
+		result := []drumNote{}
+		for _, note := range notes {
+			result = append(result, drumNote{note.TimeStamp, note.fretIndex, note.cymbal})
+		}
+		return result
+	}
+
+	withoutDoubleKick := toDrumNotes(createDrumPlayableNotes(rawNotes, realNotes, false))
+	expected := []drumNote{
+		{0, openNoteColorIndex, false}, {0, 1, true},
+		{500, 2, false},
+		{1000, openNoteColorIndex, false}, {1000, 3, true},
+	}
+	if len(withoutDoubleKick) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, withoutDoubleKick)
+	}
+	for i := range expected {
+		if withoutDoubleKick[i] != expected[i] {
+			t.Errorf("Expected %v, got %v", expected, withoutDoubleKick)
+		}
+	}
+
+	// the double kick at 384 is at the same time as a normal kick, so it's only played once
+	withDoubleKick := toDrumNotes(createDrumPlayableNotes(rawNotes, realNotes, true))
+	expected = []drumNote{
+		{0, openNoteColorIndex, false}, {0, 1, true},
+		{500, openNoteColorIndex, false}, {500, 2, false},
+		{1000, openNoteColorIndex, false}, {1000, 3, true},
+	}
+	if len(withDoubleKick) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, withDoubleKick)
+	}
+	for i := range expected {
+		if withDoubleKick[i] != expected[i] {
+			t.Errorf("Expected %v, got %v", expected, withDoubleKick)
+		}
+	}
+}
+
+func TestPlayHit_ProDrumsScoresCymbalsSeparately(t *testing.T) {
+	chart := parseTestChart(t, proDrumsChart)
+	stngs := defaultSettings()
+	stngs.proDrums = true
+
+	track := parseTrackName("ExpertDrums")
+	model := createModelFromChart(chart, track, stngs)
+	model.chartInfo.track = track
+	model = initializeModelToStrumLineTime(model, 0)
+	model = model.PlayNote(openNoteColorIndex, 0)
+
+	// the yellow note at 0 is a cymbal and is in a chord with the kick, so hitting the tom is wrong
+	tomModel := model.PlayNote(1, 0)
+	if tomModel.realTimeNotes[1].played {
+		t.Error("Expected a tom hit to not play the cymbal")
+	}
+
+	cymbalModel := model.playHit(1, true, 0)
+	if !cymbalModel.realTimeNotes[1].played {
+		t.Error("Expected a cymbal hit to play the cymbal")
+	}
+
+	// without pro drums either hit is fine
+	stngs.proDrums = false
+	tomModel = model.PlayNote(1, 0)
+	if !tomModel.realTimeNotes[1].played {
+		t.Error("Expected a tom hit to play the cymbal without pro drums")
+	}
+}
+
+func TestFretboardView_DrawsCymbals(t *testing.T) {
+	chart := parseTestChart(t, proDrumsChart)
+	track := parseTrackName("ExpertDrums")
+	model := createModelFromChart(chart, track, defaultSettings())
+	model.chartInfo.track = track
+	model.currentTimeMs = 500
+	model = model.UpdateViewModel()
+
+	r := strings.Builder{}
+	model.CreateFretboardView(&r, gpSimpleNoteStyles, nil, nil, gpSimpleBeatLineStyles)
+	view := r.String()
+
+	if !strings.Contains(view, "/2\\") {
+		t.Error("Expected the yellow cymbal to be drawn, got", view)
+	}
+	if !strings.Contains(view, "(3)") {
+		t.Error("Expected the blue tom to be drawn, got", view)
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"math/rand/v2"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+)
+
+// how many of the last random picks are avoided
+const recentRandomPicksToAvoid = 10
+
+// the songs in the folder and its sub folders. playlists are skipped since their songs are already in the library
+func (fldr *songFolder) leafSongs() []*songFolder {
+	songs := []*songFolder{}
+	for _, f := range fldr.subFolders {
+		if f.isLeaf {
+			songs = append(songs, f)
+		} else if f.playlistName == "" {
+			songs = append(songs, f.leafSongs()...)
+		}
+	}
+	return songs
+}
+
+// picks one of the songs at random, avoiding the songs that were picked recently. the most
+// recent picks are avoided first, so there's always a song to pick
+func pickRandomSong(songs []*songFolder, recentPicks []string, intN func(int) int) *songFolder {
+	avoidCount := min(len(recentPicks), len(songs)-1)
+	avoid := make(map[string]bool)
+	for _, p := range recentPicks[len(recentPicks)-avoidCount:] {
+		avoid[p] = true
+	}
+
+	candidates := []*songFolder{}
+	for _, f := range songs {
+		if !avoid[f.path] {
+			candidates = append(candidates, f)
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = songs
+	}
+	return candidates[intN(len(candidates))]
+}
+
+func rememberRandomPick(recentPicks []string, songPath string) []string {
+	recentPicks = append(recentPicks, songPath)
+	if len(recentPicks) > recentRandomPicksToAvoid {
+		recentPicks = recentPicks[len(recentPicks)-recentRandomPicksToAvoid:]
+	}
+	return recentPicks
+}
+
+// highlights a random song from the whole library, the selected folder or the favorites.
+// songs that the song list's filters would hide aren't picked
+func (m selectSongModel) highlightRandomSong(key string) (selectSongModel, tea.Cmd) {
+	var scope *songFolder
+	switch key {
+	case "r":
+		scope = m.rootSongFolder
+	case "R":
+		scope = m.selectedSongFolder
+	case "F":
+		scope = m.rootSongFolder.playlistFolder(favoritesPlaylistName)
+	}
+	if scope == nil {
+		return m, nil
+	}
+
+	filter := m.settings.songListOrder.filter
+	candidates := []*songFolder{}
+	for _, f := range scope.leafSongs() {
+		if filter.matchesInfo(f) {
+			candidates = append(candidates, f)
+		}
+	}
+
+	for len(candidates) > 0 {
+		picked := pickRandomSong(candidates, m.settings.recentRandomPicks, rand.IntN)
+		// the scores are only loaded for the folders that have been opened, and are needed for some filters
+		initializeScores(picked.parent, m.songScores, m.songPlays, m.songIntensities)
+		if !filter.matches(picked) {
+			candidates = removeSongFolder(candidates, picked)
+			continue
+		}
+
+		m.settings.recentRandomPicks = rememberRandomPick(m.settings.recentRandomPicks, picked.path)
+		log.Info("Picked a random song", "path", picked.path)
+		if picked.parent.playlistName != "" {
+			// stay in the playlist
+			return m.setSelectedSongFolder(picked.parent, picked)
+		}
+		m, cmd, err := m.highlightSongAbsolutePath(picked.path)
+		if err != nil {
+			log.Error("Failed to highlight the random song", "path", picked.path, "err", err)
+		}
+		return m, cmd
+	}
+	return m, nil
+}
+
+func removeSongFolder(folders []*songFolder, folder *songFolder) []*songFolder {
+	result := make([]*songFolder, 0, len(folders))
+	for _, f := range folders {
+		if f != folder {
+			result = append(result, f)
+		}
+	}
+	return result
+}
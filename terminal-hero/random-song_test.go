@@ -0,0 +1,52 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestLeafSongs_SkipsPlaylists(t *testing.T) {
+	root := &songFolder{name: "root", subFolders: []*songFolder{}}
+	favorites := root.addSubFolder(favoritesPlaylistName)
+	favorites.playlistName = favoritesPlaylistName
+	one := addTestSong(root.addSubFolder("GH3"), "One", songInfo{})
+	addTestSong(root.addSubFolder("RB"), "Anthem", songInfo{})
+	favorites.subFolders = append(favorites.subFolders, one)
+
+	songs := songFolderNames(root.leafSongs())
+	if len(songs) != 2 || songs[0] != "One" || songs[1] != "Anthem" {
+		t.Error("Expected each song in the library once, got", songs)
+	}
+	if songs := favorites.leafSongs(); len(songs) != 1 {
+		t.Error("Expected the songs of the playlist itself, got", songFolderNames(songs))
+	}
+}
+
+func TestPickRandomSong_AvoidsRecentPicks(t *testing.T) {
+	root := &songFolder{name: "root", subFolders: []*songFolder{}}
+	one := addTestSong(root, "One", songInfo{})
+	slowRide := addTestSong(root, "Slow Ride", songInfo{})
+	anthem := addTestSong(root, "Anthem", songInfo{})
+	songs := []*songFolder{one, slowRide, anthem}
+	first := func(n int) int { return 0 }
+
+	if picked := pickRandomSong(songs, []string{one.path}, first); picked != slowRide {
+		t.Error("Expected the recently picked song to be skipped, got", picked.name)
+	}
+	if picked := pickRandomSong(songs, []string{one.path, slowRide.path}, first); picked != anthem {
+		t.Error("Expected the only song that wasn't picked recently, got", picked.name)
+	}
+	// with every song picked recently, only the least recent pick can be picked again
+	if picked := pickRandomSong(songs, []string{one.path, slowRide.path, anthem.path}, first); picked != one {
+		t.Error("Expected the least recent pick, got", picked.name)
+	}
+}
+
+func TestRememberRandomPick_KeepsTheLatestPicks(t *testing.T) {
+	var recent []string
+	for i := 0; i < recentRandomPicksToAvoid+3; i++ {
+		recent = rememberRandomPick(recent, string(rune('a'+i)))
+	}
+	if len(recent) != recentRandomPicksToAvoid || recent[len(recent)-1] != string(rune('a'+recentRandomPicksToAvoid+2)) {
+		t.Error("Expected only the latest picks to be kept, got", recent)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+)
+
+type intensitiesAnalyzedMsg struct {
+	// by chart hash, then track name. charts that couldn't be analyzed have no tracks
+	intensities map[string]map[string]float64
+}
+
+// analyzes the songs in the folder that haven't been analyzed yet in the background, and caches
+// their ratings in the database. the scores have to be initialized first, for the chart hashes
+func (m selectSongModel) analyzeIntensitiesCmd(sf *songFolder) tea.Cmd {
+	if m.songIntensities == nil {
+		return nil
+	}
+	// chart hash -> song folder path
+	toAnalyze := make(map[string]string)
+	for _, f := range sf.subFolders {
+		if f.isLeaf && f.chartHash != "" && f.intensities == nil && !m.analyzingIntensities[f.chartHash] {
+			toAnalyze[f.chartHash] = f.path
+			m.analyzingIntensities[f.chartHash] = true
+		}
+	}
+	if len(toAnalyze) == 0 {
+		return nil
+	}
+
+	db := m.dbAccessor
+	return func() tea.Msg {
+		result := make(map[string]map[string]float64)
+		for chartHash, folderPath := range toAnalyze {
+			intensities, err := analyzeSongIntensities(folderPath)
+			if err != nil {
+				// cached as unrated, so the error is only logged once
+				log.Error("Failed to analyze the song's intensity", "path", folderPath, "err", err)
+				intensities = map[string]float64{}
+			}
+			err = db.setTrackIntensities(chartHash, intensities)
+			if err != nil {
+				log.Error("Failed to save the song's intensity", "path", folderPath, "err", err)
+			}
+			result[chartHash] = intensities
+		}
+		return intensitiesAnalyzedMsg{result}
+	}
+}
+
+// songs that only have notes.mid aren't converted just to be analyzed, so they're unrated
+func analyzeSongIntensities(folderPath string) (map[string]float64, error) {
+	if !fileExists(filepath.Join(folderPath, "notes.chart")) {
+		return map[string]float64{}, nil
+	}
+	chart, _, _, err := initializeChart(folderPath)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]float64)
+	for trackName, ti := range chartIntensities(chart) {
+		result[trackName] = ti.rating
+	}
+	return result, nil
+}
+
+func (m selectSongModel) setSongIntensities(msg intensitiesAnalyzedMsg) (selectSongModel, tea.Cmd) {
+	if m.songIntensities == nil {
+		// the cached ratings haven't loaded yet, and will include these
+		return m, nil
+	}
+	for chartHash, intensities := range msg.intensities {
+		m.songIntensities[chartHash] = intensities
+		delete(m.analyzingIntensities, chartHash)
+	}
+	if m.selectedSongFolder == nil {
+		return m, nil
+	}
+	initializeScores(m.selectedSongFolder, m.songScores, m.songPlays, m.songIntensities)
+	return m.refreshSongList()
+}
@@ -4,6 +4,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -50,6 +51,7 @@ func initialSelectSongListModel(spkr soundPlayer, afo audioFileOpener) selectSon
 	styleList(&selectSongMenuList)
 
 	setupKeymapForList(&selectSongMenuList)
+	setupSongListOrderKeys(&selectSongMenuList)
 	model.menuList = selectSongMenuList
 
 	model.speaker = spkr
@@ -58,6 +60,33 @@ func initialSelectSongListModel(spkr soundPlayer, afo audioFileOpener) selectSon
 	return model
 }
 
+// adds the sorting, filtering, playlist, setlist, career and random song keys, which selectSongModel handles, to the full help
+func setupSongListOrderKeys(menuList *list.Model) {
+	orderKeys := []key.Binding{
+		key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "sort")),
+		key.NewBinding(key.WithKeys("u"), key.WithHelp("u", "unplayed")),
+		key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "not full combo")),
+		key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "has drums")),
+		key.NewBinding(key.WithKeys("[", "]"), key.WithHelp("[/]", "min difficulty")),
+		key.NewBinding(key.WithKeys("{", "}"), key.WithHelp("{/}", "max difficulty")),
+		key.NewBinding(key.WithKeys("x"), key.WithHelp("x", "clear filters")),
+		key.NewBinding(key.WithKeys("f"), key.WithHelp("f", "favorite")),
+		key.NewBinding(key.WithKeys("+"), key.WithHelp("+", "add to playlist")),
+		key.NewBinding(key.WithKeys("-"), key.WithHelp("-", "remove from playlist")),
+		key.NewBinding(key.WithKeys("shift+up", "shift+down"), key.WithHelp("shift+↑/↓", "move in playlist")),
+		key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "queue in setlist")),
+		key.NewBinding(key.WithKeys("ctrl+p"), key.WithHelp("ctrl+p", "play setlist")),
+		key.NewBinding(key.WithKeys("ctrl+r"), key.WithHelp("ctrl+r", "career")),
+		key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "random song")),
+		key.NewBinding(key.WithKeys("R"), key.WithHelp("R", "random song in folder")),
+		key.NewBinding(key.WithKeys("F"), key.WithHelp("F", "random favorite")),
+	}
+	fullHelpKeys := menuList.AdditionalFullHelpKeys
+	menuList.AdditionalFullHelpKeys = func() []key.Binding {
+		return append(fullHelpKeys(), orderKeys...)
+	}
+}
+
 func (m selectSongListModel) Init() tea.Cmd {
 	return nil
 }
@@ -127,6 +156,18 @@ func (m selectSongListModel) highlightedChildFolder() *songFolder {
 	return item.(*songFolder)
 }
 
+// the folder after the highlighted one, or the one before it if the highlighted one is last
+func (m selectSongListModel) neighbourOfHighlighted() *songFolder {
+	items := m.menuList.Items()
+	i := m.menuList.Index()
+	if i+1 < len(items) {
+		return items[i+1].(*songFolder)
+	} else if i > 0 {
+		return items[i-1].(*songFolder)
+	}
+	return nil
+}
+
 func (m selectSongListModel) highlightSubfolder(highlightedSubFolder *songFolder) (selectSongListModel, tea.Cmd) {
 	indexOfHighlighted := 0
 	if highlightedSubFolder != nil {
@@ -188,7 +229,12 @@ func loadPreviewSongCmd(afo audioFileOpener, previewFilePath string) tea.Cmd {
 		// load unbuffered stream
 		s, format, err := afo.openAudioFile(previewFilePath)
 		if err != nil {
-			return previewSongLoadFailedMsg{previewFilePath, err}
+			// most songs don't have a preview, so play part of the song instead
+			clip, clipFormat, clipErr := openPreviewClip(afo, filepath.Dir(previewFilePath))
+			if clipErr != nil {
+				return previewSongLoadFailedMsg{previewFilePath, clipErr}
+			}
+			return previewSongLoadedMsg{previewFilePath, sound{clip, clipFormat, previewFilePath}}
 		} else {
 			return previewSongLoadedMsg{previewFilePath, sound{s, format, previewFilePath}}
 		}
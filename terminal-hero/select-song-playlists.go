@@ -0,0 +1,166 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+)
+
+// the keys that add and remove the highlighted song, and move it within the playlist that's shown
+func (m selectSongModel) changePlaylists(key string) (selectSongModel, tea.Cmd) {
+	sf, ok := m.songList.selectedItem()
+	if !ok || !sf.isLeaf {
+		return m, nil
+	}
+
+	switch key {
+	case "f":
+		ch, err := sf.songChartHash()
+		if err != nil {
+			log.Error("Failed to hash chart", "path", sf.path, "err", err)
+			return m, nil
+		}
+		if m.rootSongFolder.playlistContains(favoritesPlaylistName, ch) {
+			return m.removeFromPlaylist(favoritesPlaylistName, sf)
+		}
+		return m.addToPlaylist(favoritesPlaylistName, sf)
+	case "+":
+		return m.startAddingToPlaylist(sf)
+	case "-":
+		if m.viewingPlaylist() {
+			return m.removeFromPlaylist(m.selectedSongFolder.playlistName, sf)
+		}
+	case "shift+up":
+		return m.movePlaylistSong(sf, -1)
+	case "shift+down":
+		return m.movePlaylistSong(sf, 1)
+	}
+	return m, nil
+}
+
+// playlists can only be changed from the playlist's folder, not from search results
+func (m selectSongModel) viewingPlaylist() bool {
+	return m.selectedSongFolder.playlistName != "" && m.searchState == ssNotSearching
+}
+
+func (m selectSongModel) addToPlaylist(name string, sf *songFolder) (selectSongModel, tea.Cmd) {
+	s, err := sf.song()
+	if err != nil {
+		log.Error("Failed to add song to playlist", "playlist", name, "path", sf.path, "err", err)
+		return m, nil
+	}
+	if m.rootSongFolder.playlistContains(name, s.ChartHash) {
+		return m, nil
+	}
+
+	err = m.dbAccessor.addToPlaylist(name, s)
+	if err != nil {
+		log.Error("Failed to add song to playlist", "playlist", name, "song", s.Name, "err", err)
+		return m, nil
+	}
+	m.rootSongFolder.addToPlaylistFolder(name, sf)
+	log.Info("Added song to playlist", "playlist", name, "song", s.Name)
+	return m.refreshSongList()
+}
+
+func (m selectSongModel) removeFromPlaylist(name string, sf *songFolder) (selectSongModel, tea.Cmd) {
+	ch, err := sf.songChartHash()
+	if err != nil {
+		log.Error("Failed to hash chart", "path", sf.path, "err", err)
+		return m, nil
+	}
+
+	err = m.dbAccessor.removeFromPlaylist(name, ch)
+	if err != nil {
+		log.Error("Failed to remove song from playlist", "playlist", name, "song", sf.name, "err", err)
+		return m, nil
+	}
+
+	// the song disappears from the playlist that's shown, so the one after it is highlighted
+	highlighted := m.songList.highlightedChildFolder()
+	if m.selectedSongFolder.playlistName == name && highlighted == sf {
+		highlighted = m.songList.neighbourOfHighlighted()
+	}
+	m.rootSongFolder.removeFromPlaylistFolder(name, ch)
+	log.Info("Removed song from playlist", "playlist", name, "song", sf.name)
+
+	if m.searchState != ssNotSearching {
+		return m, nil
+	}
+	return m.showSongFolder(m.selectedSongFolder, highlighted)
+}
+
+// swaps the song with the one that's shown above or below it
+func (m selectSongModel) movePlaylistSong(sf *songFolder, delta int) (selectSongModel, tea.Cmd) {
+	if !m.viewingPlaylist() {
+		return m, nil
+	}
+	items := m.songList.menuList.Items()
+	otherIndex := m.songList.menuList.Index() + delta
+	if otherIndex < 0 || otherIndex >= len(items) {
+		return m, nil
+	}
+	other := items[otherIndex].(*songFolder)
+
+	pf := m.selectedSongFolder
+	err := m.dbAccessor.swapPlaylistSongs(pf.playlistName, sf.chartHash, other.chartHash)
+	if err != nil {
+		log.Error("Failed to move song in playlist", "playlist", pf.playlistName, "song", sf.name, "err", err)
+		return m, nil
+	}
+	pf.swapPlaylistEntries(sf, other)
+	return m.refreshSongList()
+}
+
+func (m selectSongModel) startAddingToPlaylist(sf *songFolder) (selectSongModel, tea.Cmd) {
+	ti := textinput.New()
+	ti.Placeholder = "Playlist name"
+	ti.CharLimit = 100
+	ti.Width = 30
+	ti.Focus()
+	m.playlistTi = &ti
+	m.playlistSong = sf
+	m = m.updateSongListSize()
+	return m, textinput.Blink
+}
+
+func (m selectSongModel) stopAddingToPlaylist() selectSongModel {
+	m.playlistTi = nil
+	m.playlistSong = nil
+	return m.updateSongListSize()
+}
+
+// sends keys to the playlist name text box. enter adds the song and esc cancels
+func (m selectSongModel) UpdateAddingToPlaylist(msg tea.Msg) (selectSongModel, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "esc":
+			return m.stopAddingToPlaylist(), nil
+		case "enter":
+			name := strings.TrimSpace(m.playlistTi.Value())
+			sf := m.playlistSong
+			m = m.stopAddingToPlaylist()
+			if name == "" {
+				return m, nil
+			}
+			return m.addToPlaylist(name, sf)
+		}
+	}
+
+	ti, tiCmd := m.playlistTi.Update(msg)
+	m.playlistTi = &ti
+	return m, tiCmd
+}
+
+func (m selectSongModel) playlistPromptView() string {
+	b := strings.Builder{}
+	b.WriteString("Add " + m.playlistSong.name + " to playlist:\n")
+	b.WriteString(m.playlistTi.View() + "\n")
+	names := m.rootSongFolder.playlistNames()
+	if len(names) > 0 {
+		b.WriteString("Playlists: " + strings.Join(names, ", "))
+	}
+	return b.String()
+}
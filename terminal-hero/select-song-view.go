@@ -51,6 +51,12 @@ func (m selectSongModel) View() string {
 		} else {
 			menuListView = m.searchTi.View() + "\n\n" + m.songList.View()
 		}
+		if m.playlistTi != nil {
+			menuListView = m.playlistPromptView() + "\n\n" + menuListView
+		}
+		if m.setlist != nil && len(m.setlist.songPaths) > 0 {
+			menuListView += "\n" + m.setlist.description() + " (ctrl+p to play)"
+		}
 
 	}
 
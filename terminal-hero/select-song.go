@@ -1,9 +1,9 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/textinput"
@@ -22,8 +22,15 @@ type selectSongModel struct {
 	rootPath                     string
 	songList                     selectSongListModel
 	selectedSongPath             string
+	editSelectedSong             bool
+	startSetlist                 bool // play the queued songs, starting with the first
+	openCareer                   bool
+	setlist                      *setlist
 	dbAccessor                   grDbAccessor
 	songScores                   *map[string]songScore
+	songPlays                    map[string]songPlays
+	songIntensities              map[string]map[string]float64 // by chart hash, then track name
+	analyzingIntensities         map[string]bool               // the chart hashes being analyzed in the background
 	defaultHighlightRelativePath string
 	settings                     *settings
 
@@ -31,6 +38,12 @@ type selectSongModel struct {
 	searchState searchState
 	searchStr   string
 	searchTi    *textinput.Model
+	// the letters of each search result's title that matched
+	searchMatches map[*songFolder][]int
+
+	// typing the name of the playlist to add playlistSong to
+	playlistTi   *textinput.Model
+	playlistSong *songFolder
 }
 
 type searchState int
@@ -41,9 +54,11 @@ const (
 	ssNavigatingSearchResults
 )
 
-func initialSelectSongModel(rootPath string, dbAccessor grDbAccessor, settings *settings, spkr *thSpeaker) selectSongModel {
+func initialSelectSongModel(rootPath string, dbAccessor grDbAccessor, settings *settings, spkr *thSpeaker, sl *setlist) selectSongModel {
 	model := selectSongModel{}
 	model.settings = settings
+	model.setlist = sl
+	model.analyzingIntensities = make(map[string]bool)
 
 	var songOpener defaultAudioFileOpener
 	model.songList = initialSelectSongListModel(spkr, songOpener)
@@ -60,40 +75,56 @@ func (m selectSongModel) updateSongListSize() selectSongModel {
 	if m.searchState != ssNotSearching {
 		height = m.settings.windowHeight - 22
 	}
+	if m.playlistTi != nil {
+		height -= 4
+	}
+	if m.setlist != nil && len(m.setlist.songPaths) > 0 {
+		height -= 2
+	}
 	log.Info("Updating song list size to ", "height", height)
 	m.songList = m.songList.setSize(70, height)
 	return m
 }
 
-func initializeScores(flder *songFolder, ss *map[string]songScore) {
+func initializeScores(flder *songFolder, ss *map[string]songScore, plays map[string]songPlays, intensities map[string]map[string]float64) {
+	if ss == nil {
+		// the scores haven't loaded yet
+		return
+	}
 	for _, f := range flder.subFolders {
 		if f.isLeaf {
-			chartPath := filepath.Join(f.path, "notes.chart")
-			if !fileExists(chartPath) {
+			ch, err := f.songChartHash()
+			if errors.Is(err, os.ErrNotExist) {
 				continue
-			}
-
-			ch, err := hashFileByPath(chartPath)
-			if err != nil && err != os.ErrNotExist {
+			} else if err != nil {
 				panic(err)
 			}
 
 			f.songScore = (*ss)[ch]
+			f.plays = plays[ch]
+			f.intensities = intensities[ch]
 		}
 	}
 }
 
 type trackScoresLoadedMsg struct {
-	trackScores *map[string]songScore
+	trackScores     *map[string]songScore
+	songPlays       map[string]songPlays
+	songIntensities map[string]map[string]float64
 }
 
 type songFoldersLoadedMsg struct {
 	rootSongFolder *songFolder
 }
 
-func initializeSongFoldersCmd(rootPath string) tea.Cmd {
+func initializeSongFoldersCmd(rootPath string, dbAccessor grDbAccessor) tea.Cmd {
 	return func() tea.Msg {
-		return songFoldersLoadedMsg{loadSongFolder(rootPath)}
+		root := loadSongFolder(rootPath)
+		err := loadPlaylistFolders(root, dbAccessor)
+		if err != nil {
+			log.Error("Failed to load playlists", "err", err)
+		}
+		return songFoldersLoadedMsg{root}
 	}
 }
 
@@ -103,12 +134,20 @@ func initializeTrackScoresCmd(dbAccessor grDbAccessor) tea.Cmd {
 		if err != nil {
 			panic(err)
 		}
-		return trackScoresLoadedMsg{ss}
+		plays, err := dbAccessor.getSongPlays()
+		if err != nil {
+			panic(err)
+		}
+		intensities, err := dbAccessor.getTrackIntensities()
+		if err != nil {
+			panic(err)
+		}
+		return trackScoresLoadedMsg{ss, plays, intensities}
 	}
 }
 
 func (m selectSongModel) Init() tea.Cmd {
-	return tea.Batch(initializeSongFoldersCmd(m.rootPath), initializeTrackScoresCmd(m.dbAccessor), textinput.Blink)
+	return tea.Batch(initializeSongFoldersCmd(m.rootPath, m.dbAccessor), initializeTrackScoresCmd(m.dbAccessor), textinput.Blink)
 }
 
 func (m selectSongModel) stopSearching() (selectSongModel, tea.Cmd) {
@@ -128,13 +167,14 @@ func (m selectSongModel) startSearching() (selectSongModel, tea.Cmd) {
 	m.updateSongListSize()
 	m.searchTi.Focus()
 	m.rootSongFolder.context.searching = true
-	m.songList.menuList.SetDelegate(createListDdNoStyling())
+	m.searchMatches = nil
+	m.songList.menuList.SetDelegate(createListDdNoStyling(m.searchMatches))
 	return m, textinput.Blink
 }
 
 func (m selectSongModel) reEditSearch() (selectSongModel, tea.Cmd) {
 	m.searchState = ssSearching
-	m.songList.menuList.SetDelegate(createListDdNoStyling())
+	m.songList.menuList.SetDelegate(createListDdNoStyling(m.searchMatches))
 	m.searchTi.Focus()
 	return m, textinput.Blink
 }
@@ -172,7 +212,13 @@ func (m selectSongModel) UpdateSearching(msg tea.Msg) (selectSongModel, tea.Cmd)
 
 	if newSearchStr != m.searchStr {
 
-		results := m.selectedSongFolder.search(newSearchStr)
+		results := m.selectedSongFolder.rankedSearch(newSearchStr)
+		folders := make([]*songFolder, len(results))
+		m.searchMatches = make(map[*songFolder][]int)
+		for i, result := range results {
+			folders[i] = result.folder
+			m.searchMatches[result.folder] = result.titleMatches
+		}
 
 		searchLocationStr := m.selectedSongFolder.name
 		if m.selectedSongFolder.parent != nil {
@@ -182,7 +228,8 @@ func (m selectSongModel) UpdateSearching(msg tea.Msg) (selectSongModel, tea.Cmd)
 			}
 		}
 
-		m.songList, _ = m.songList.setSongs(results, nil, "Search results in "+searchLocationStr)
+		m.songList, _ = m.songList.setSongs(folders, nil, "Search results in "+searchLocationStr)
+		m.songList.menuList.SetDelegate(createListDdNoStyling(m.searchMatches))
 		m.searchStr = newSearchStr
 	}
 
@@ -190,6 +237,13 @@ func (m selectSongModel) UpdateSearching(msg tea.Msg) (selectSongModel, tea.Cmd)
 }
 
 func (m selectSongModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(intensitiesAnalyzedMsg); ok {
+		// handled here so that it isn't lost while typing
+		return m.setSongIntensities(msg)
+	}
+	if m.playlistTi != nil {
+		return m.UpdateAddingToPlaylist(msg)
+	}
 	if m.searchState == ssSearching {
 		return m.UpdateSearching(msg)
 	}
@@ -214,6 +268,15 @@ func (m selectSongModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					return m.setSelectedSongFolder(i, nil)
 				}
 			}
+		case "ctrl+e":
+			i, ok := m.songList.selectedItem()
+			if ok && i.isLeaf {
+				m.songList.destroy()
+				resultModel := selectSongModel{}
+				resultModel.selectedSongPath = i.path
+				resultModel.editSelectedSong = true
+				return resultModel, nil
+			}
 		case "ctrl+f":
 			if m.searchState == ssNotSearching {
 				return m.startSearching()
@@ -224,6 +287,37 @@ func (m selectSongModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.searchState != ssNotSearching {
 				return m.stopSearching()
 			}
+		case "o", "u", "c", "p", "[", "]", "{", "}", "x":
+			if m.searchState == ssNotSearching {
+				return m.changeSongListOrder(msg.String())
+			}
+			// search results are in order of how well they match
+			slm, mlCmd := m.songList.Update(msg)
+			m.songList = slm.(selectSongListModel)
+			return m, mlCmd
+		case " ":
+			i, ok := m.songList.selectedItem()
+			if ok && i.isLeaf {
+				m.setlist.toggle(i.path)
+				m = m.updateSongListSize()
+			}
+			return m, nil
+		case "ctrl+p":
+			return m.playSetlist()
+		case "r", "R", "F":
+			if m.searchState == ssNotSearching {
+				return m.highlightRandomSong(msg.String())
+			}
+			slm, mlCmd := m.songList.Update(msg)
+			m.songList = slm.(selectSongListModel)
+			return m, mlCmd
+		case "ctrl+r":
+			m.songList.destroy()
+			resultModel := selectSongModel{}
+			resultModel.openCareer = true
+			return resultModel, nil
+		case "f", "+", "-", "shift+up", "shift+down":
+			return m.changePlaylists(msg.String())
 		case "backspace":
 			if m.searchState == ssNavigatingSearchResults {
 
@@ -261,41 +355,111 @@ func (m selectSongModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m, _ = m.highlightSongRelativePath(m.defaultHighlightRelativePath)
 			m.defaultHighlightRelativePath = ""
 		} else {
-			m, cmd := m.setSelectedSongFolder(m.rootSongFolder, nil)
-			if m.loaded() {
-				initializeScores(m.rootSongFolder, m.songScores)
-			}
-			return m, cmd
+			return m.setSelectedSongFolder(m.rootSongFolder, nil)
 		}
 	case trackScoresLoadedMsg:
 		m.songScores = msg.trackScores
-		if m.loaded() {
-			initializeScores(m.rootSongFolder, m.songScores)
+		m.songPlays = msg.songPlays
+		m.songIntensities = msg.songIntensities
+		if m.loaded() && m.selectedSongFolder != nil {
+			initializeScores(m.selectedSongFolder, m.songScores, m.songPlays, m.songIntensities)
+			var cmd tea.Cmd
+			m, cmd = m.refreshSongList()
+			return m, tea.Batch(cmd, m.analyzeIntensitiesCmd(m.selectedSongFolder))
 		}
 	}
 	return m, nil
 }
 
-func songFolderTitle(sf *songFolder) string {
+// starts the queued songs. with nothing queued, the songs of the playlist that's shown are played
+func (m selectSongModel) playSetlist() (selectSongModel, tea.Cmd) {
+	if len(m.setlist.songPaths) == 0 && m.viewingPlaylist() {
+		for _, f := range m.selectedSongFolder.subFolders {
+			m.setlist.toggle(f.path)
+		}
+	}
+	if len(m.setlist.songPaths) == 0 {
+		return m, nil
+	}
+
+	m.songList.destroy()
+	resultModel := selectSongModel{}
+	resultModel.startSetlist = true
+	return resultModel, nil
+}
+
+func songFolderTitle(sf *songFolder, order songListOrder, shownSongs int) string {
 	var title string
 	relativePath, err := sf.relativePath()
 	suffix := fmt.Sprintf(" (%d songs)", sf.songCount)
+	if order.filter.active() && sf.hasSongs() {
+		suffix = fmt.Sprintf(" (%d songs, %d shown)", sf.songCount, shownSongs)
+	}
 	if err != nil || relativePath == "" {
 		title = sf.name + suffix
 	} else {
 		title = strings.Replace(relativePath, "\\", "/", -1) + suffix
 	}
-	return title
+	return title + " " + order.description(sf.playlistName != "")
+}
+
+// the keys that change the sort mode and filters
+func (m selectSongModel) changeSongListOrder(key string) (selectSongModel, tea.Cmd) {
+	order := &m.settings.songListOrder
+	switch key {
+	case "o":
+		order.sortMode = order.sortMode.next()
+	case "u":
+		order.filter.unplayed = !order.filter.unplayed
+	case "c":
+		order.filter.notFullCombo = !order.filter.notFullCombo
+	case "p":
+		order.filter.hasDrums = !order.filter.hasDrums
+	case "[":
+		order.filter = order.filter.changeMinDifficulty(-1)
+	case "]":
+		order.filter = order.filter.changeMinDifficulty(1)
+	case "{":
+		order.filter = order.filter.changeMaxDifficulty(-1)
+	case "}":
+		order.filter = order.filter.changeMaxDifficulty(1)
+	case "x":
+		order.filter = defaultSongListOrder().filter
+	}
+	return m.refreshSongList()
+}
+
+// re-sorts and re-filters the selected folder, keeping the highlighted song if it's still shown
+func (m selectSongModel) refreshSongList() (selectSongModel, tea.Cmd) {
+	if m.selectedSongFolder == nil || m.searchState != ssNotSearching {
+		return m, nil
+	}
+	return m.showSongFolder(m.selectedSongFolder, m.songList.highlightedChildFolder())
+}
+
+func (m selectSongModel) showSongFolder(sf *songFolder, highlightedSubFolder *songFolder) (selectSongModel, tea.Cmd) {
+	order := m.settings.songListOrder
+	folders := sortAndFilterSongs(sf.subFolders, order, sf.playlistName != "")
+	shownSongs := 0
+	for _, f := range folders {
+		if f.isLeaf {
+			shownSongs++
+		}
+	}
+
+	var cmd tea.Cmd
+	m.songList, cmd = m.songList.setSongs(folders, highlightedSubFolder, songFolderTitle(sf, order, shownSongs))
+	return m, cmd
 }
 
 func (m selectSongModel) setSelectedSongFolder(sf *songFolder, highlightedSubFolder *songFolder) (selectSongModel, tea.Cmd) {
-	title := songFolderTitle(sf)
+	// the scores are needed to sort and filter
+	initializeScores(sf, m.songScores, m.songPlays, m.songIntensities)
 
 	var ssCmd tea.Cmd
-	m.songList, ssCmd = m.songList.setSongs(sf.subFolders, highlightedSubFolder, title)
+	m, ssCmd = m.showSongFolder(sf, highlightedSubFolder)
 
 	m.selectedSongFolder = sf
-	initializeScores(sf, m.songScores)
 
 	if m.searchState != ssNotSearching {
 		m.searchState = ssNotSearching
@@ -306,7 +470,7 @@ func (m selectSongModel) setSelectedSongFolder(sf *songFolder, highlightedSubFol
 		m.updateSongListSize()
 	}
 
-	return m, ssCmd
+	return m, tea.Batch(ssCmd, m.analyzeIntensitiesCmd(sf))
 }
 
 func (m selectSongModel) highlightSongAbsolutePath(absolutePath string) (selectSongModel, tea.Cmd, error) {
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// songs that are played back to back. the stats screen of each song goes on to the next one,
+// and a summary of all of them is shown at the end
+type setlist struct {
+	songPaths []string // the folders of the queued songs
+	playing   bool
+	current   int // the index of the song being played
+	// the track picked for the first song, which is picked automatically for the other songs that have it
+	track   *trackName
+	results []setlistResult
+}
+
+type setlistResult struct {
+	chartInfo chartInfo
+	playStats playStats
+}
+
+func (s *setlist) contains(songPath string) bool {
+	return s.indexOf(songPath) >= 0
+}
+
+func (s *setlist) indexOf(songPath string) int {
+	for i, p := range s.songPaths {
+		if p == songPath {
+			return i
+		}
+	}
+	return -1
+}
+
+// queues the song, or takes it out of the queue if it's already queued
+func (s *setlist) toggle(songPath string) {
+	if i := s.indexOf(songPath); i >= 0 {
+		s.songPaths = append(s.songPaths[:i], s.songPaths[i+1:]...)
+	} else {
+		s.songPaths = append(s.songPaths, songPath)
+	}
+}
+
+func (s *setlist) start() {
+	s.playing = true
+	s.current = 0
+	s.track = nil
+	s.results = []setlistResult{}
+}
+
+// stops playing, keeping the queue so the setlist can be started again
+func (s *setlist) stop() {
+	s.playing = false
+}
+
+func (s *setlist) currentSongPath() string {
+	return s.songPaths[s.current]
+}
+
+func (s *setlist) addResult(ci chartInfo, ps playStats) {
+	s.results = append(s.results, setlistResult{ci, ps})
+}
+
+// moves on to the next song. returns false once every song has been played
+func (s *setlist) next() bool {
+	s.current++
+	return s.current < len(s.songPaths)
+}
+
+// clears the queue once the setlist is over
+func (s *setlist) finish() {
+	s.playing = false
+	s.songPaths = nil
+}
+
+// what the song list shows about the queued songs
+func (s *setlist) description() string {
+	names := make([]string, len(s.songPaths))
+	for i, p := range s.songPaths {
+		names[i] = filepath.Base(p)
+	}
+	return fmt.Sprintf("Setlist (%d): %s", len(names), strings.Join(names, ", "))
+}
+
+type setlistSummaryModel struct {
+	results        []setlistResult
+	shouldContinue bool
+}
+
+func initialSetlistSummaryModel(results []setlistResult) setlistSummaryModel {
+	return setlistSummaryModel{results: results}
+}
+
+func (m setlistSummaryModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m setlistSummaryModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "enter":
+			m.shouldContinue = true
+		}
+	}
+	return m, nil
+}
+
+// the totals over every song. the notes of failed songs that weren't reached count as missed
+func (m setlistSummaryModel) totals() (score int, notesHit int, totalNotes int, passed int) {
+	for _, r := range m.results {
+		score += r.playStats.score
+		notesHit += r.playStats.notesHitGrouped
+		totalNotes += r.playStats.totalNotes
+		if !r.playStats.failed {
+			passed++
+		}
+	}
+	return score, notesHit, totalNotes, passed
+}
+
+func (m setlistSummaryModel) View() string {
+	sb := strings.Builder{}
+	sb.WriteString(passStyle.Render("Setlist complete") + "\n\n")
+
+	score, notesHit, totalNotes, passed := m.totals()
+	accuracy := 0.0
+	if totalNotes > 0 {
+		accuracy = float64(notesHit) / float64(totalNotes)
+	}
+	sl := statsList{}
+	sl.add("Songs passed", fmt.Sprintf("%d/%d", passed, len(m.results)))
+	sl.add("Total score", fmt.Sprintf("%d", score))
+	sl.add("Accuracy", fmt.Sprintf("%.0f", accuracy*100)+"%")
+	sl.add("Notes hit", fmt.Sprintf("%d/%d", notesHit, totalNotes))
+	sb.WriteString(statsListStyle.Render(sl.View()) + "\n\n")
+
+	songs := statsList{}
+	for i, r := range m.results {
+		name := fmt.Sprintf("%d. %s", i+1, r.chartInfo.songName())
+		if r.playStats.failed {
+			songs.add(name, failedStyle.Render("Failed")+fmt.Sprintf(" (%d notes hit)", r.playStats.notesHitGrouped))
+		} else {
+			songs.add(name, fmt.Sprintf("%d (%.0f%%) ", r.playStats.score, r.playStats.percentage()*100)+
+				starStyle.Render(smallStarString(r.playStats.starCount())))
+		}
+	}
+	sb.WriteString(statsListStyle.Render(songs.View()))
+
+	sb.WriteString(lipgloss.NewStyle().Background(lipgloss.Color("#b6b3fc")).Foreground(lipgloss.Color("#000000")).
+		Padding(1, 3, 1, 3).Margin(3, 1, 1, 2).Bold(true).Render("Press ENTER To continue"))
+
+	return sb.String()
+}
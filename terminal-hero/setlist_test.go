@@ -0,0 +1,65 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestSetlist_PlaysEachSongThenFinishes(t *testing.T) {
+	sl := &setlist{}
+	sl.toggle("Songs/One")
+	sl.toggle("Songs/Slow Ride")
+	sl.toggle("Songs/Anthem")
+	sl.toggle("Songs/Slow Ride")
+	if len(sl.songPaths) != 2 || sl.songPaths[1] != "Songs/Anthem" {
+		t.Fatal("Expected toggling a queued song to take it out of the queue, got", sl.songPaths)
+	}
+
+	sl.start()
+	if sl.currentSongPath() != "Songs/One" {
+		t.Error("Expected the first song to be played first, got", sl.currentSongPath())
+	}
+	if !sl.next() || sl.currentSongPath() != "Songs/Anthem" {
+		t.Error("Expected the second song to be played next")
+	}
+	if sl.next() {
+		t.Error("Expected the setlist to be over after the last song")
+	}
+
+	sl.finish()
+	if sl.playing || len(sl.songPaths) != 0 {
+		t.Error("Expected the queue to be cleared once the setlist is over")
+	}
+}
+
+func TestSetlistSummary_Totals(t *testing.T) {
+	summary := initialSetlistSummaryModel([]setlistResult{
+		{chartInfo{}, playStats{totalNotes: 100, notesHitGrouped: 90, score: 5000}},
+		{chartInfo{}, playStats{totalNotes: 300, notesHitGrouped: 30, score: 1000, failed: true}},
+	})
+
+	score, notesHit, totalNotes, passed := summary.totals()
+	if score != 6000 || notesHit != 120 || totalNotes != 400 || passed != 1 {
+		t.Errorf("Expected 6000 points, 120/400 notes and 1 song passed, got %d points, %d/%d notes and %d passed",
+			score, notesHit, totalNotes, passed)
+	}
+}
+
+func TestLoadSongModel_SelectsTheSetlistTrack(t *testing.T) {
+	chart := openCultOfPersonalityChart(t)
+
+	m := initialLoadModel("", defaultSettings(), nil, nil)
+	m.autoSelectTrack = "HardSingle"
+	lm, _ := m.Update(loadedChartMsg{chart: chart})
+	m = lm.(loadSongModel)
+	if m.selectedTrack == nil || m.selectedTrack.fullTrackName != "HardSingle" {
+		t.Error("Expected HardSingle to be picked, got", m.selectedTrack)
+	}
+
+	m = initialLoadModel("", defaultSettings(), nil, nil)
+	m.autoSelectTrack = "ExpertKeyboard"
+	lm, _ = m.Update(loadedChartMsg{chart: chart})
+	m = lm.(loadSongModel)
+	if m.selectedTrack != nil || m.selectedInstrument != nil {
+		t.Error("Expected to be asked for the track when the chart doesn't have it, got", m.selectedTrack)
+	}
+}
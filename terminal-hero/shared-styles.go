@@ -1,6 +1,8 @@
 package main
 
 import (
+	"io"
+
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -58,7 +60,32 @@ func createListDd(hasDesc bool) list.DefaultDelegate {
 	return dd
 }
 
-func createListDdNoStyling() list.DefaultDelegate {
+// highlights the letters of the titles that matched the search
+type searchResultsDelegate struct {
+	list.DefaultDelegate
+	matches map[*songFolder][]int
+}
+
+func (d searchResultsDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	if sf, ok := item.(*songFolder); ok && len(d.matches[sf]) > 0 {
+		unmatched := d.Styles.NormalTitle.Copy().Inline(true)
+		matched := unmatched.Copy().Inherit(d.Styles.FilterMatch)
+		title := lipgloss.StyleRunes(sf.Title(), d.matches[sf], matched, unmatched)
+		item = list.Item(highlightedSongFolder{sf, title})
+	}
+	d.DefaultDelegate.Render(w, m, index, item)
+}
+
+type highlightedSongFolder struct {
+	*songFolder
+	title string
+}
+
+func (i highlightedSongFolder) Title() string {
+	return i.title
+}
+
+func createListDdNoStyling(matches map[*songFolder][]int) searchResultsDelegate {
 	// for search results. selected item shouldn't be highlighted
 	dd := list.NewDefaultDelegate()
 
@@ -69,5 +96,5 @@ func createListDdNoStyling() list.DefaultDelegate {
 	dd.Styles.NormalTitle = dd.Styles.SelectedTitle
 	dd.Styles.NormalDesc = dd.Styles.SelectedDesc
 
-	return dd
+	return searchResultsDelegate{dd, matches}
 }
@@ -20,6 +20,13 @@ type songFolder struct {
 	songCount  int
 	songScore  songScore
 	context    *songFolderContext
+	info       *songInfo // only songs have info. nil until loadInfo reads it
+	chartHash  string    // cached the first time the song's scores are looked up
+	plays      songPlays
+	// the intensity rating of each track, nil until the chart has been analyzed
+	intensities map[string]float64
+	// set on the virtual folders that list a playlist's songs, which are at the top of the root folder
+	playlistName string
 }
 
 type songFolderContext struct {
@@ -65,8 +72,13 @@ func (i *songFolder) Description() string {
 		b := strings.Builder{}
 		first := true
 
+		if intensity := i.intensity(); intensity >= 0 {
+			b.WriteString(fmt.Sprintf("Intensity %.1f | ", intensity))
+		}
+
 		if len(i.songScore.TrackScores) == 0 {
-			return "Never passed"
+			b.WriteString("Never passed")
+			return b.String()
 		}
 
 		for k, v := range i.songScore.TrackScores {
@@ -124,8 +136,8 @@ func populateSongFolder(fldr *songFolder) {
 
 	for _, f := range files {
 		if f.IsDir() {
-			child := &songFolder{f.Name(), filepath.Join(fldr.path, f.Name()),
-				fldr, []*songFolder{}, false, 0, songScore{}, fldr.context}
+			child := &songFolder{name: f.Name(), path: filepath.Join(fldr.path, f.Name()),
+				parent: fldr, subFolders: []*songFolder{}, context: fldr.context}
 			fldr.subFolders = append(fldr.subFolders, child)
 			populateSongFolder(child)
 		} else {
@@ -138,6 +150,16 @@ func populateSongFolder(fldr *songFolder) {
 	}
 }
 
+// the song's info, which is only read the first time that searching, sorting or filtering needs it.
+// nil for folders that aren't songs
+func (fldr *songFolder) loadInfo() *songInfo {
+	if fldr.info == nil && fldr.isLeaf {
+		info := readSongInfo(fldr.path)
+		fldr.info = &info
+	}
+	return fldr.info
+}
+
 func trimSongFolders(fldr *songFolder) {
 	for i := len(fldr.subFolders) - 1; i >= 0; i-- {
 		if fldr.subFolders[i].songCount == 0 {
@@ -160,7 +182,7 @@ func (fldr *songFolder) queryFolder(path []string) *songFolder {
 
 func (fldr *songFolder) getSubfolder(name string) *songFolder {
 	for _, f := range fldr.subFolders {
-		if f.name == name {
+		if f.name == name && f.playlistName == "" {
 			return f
 		}
 	}
@@ -168,28 +190,20 @@ func (fldr *songFolder) getSubfolder(name string) *songFolder {
 }
 
 func (fldr *songFolder) addSubFolder(name string) *songFolder {
-	f := &songFolder{name, filepath.Join(fldr.path, name), fldr, []*songFolder{},
-		false, 0, songScore{}, fldr.context}
+	f := &songFolder{name: name, path: filepath.Join(fldr.path, name), parent: fldr,
+		subFolders: []*songFolder{}, context: fldr.context}
 	fldr.subFolders = append(fldr.subFolders, f)
 	return f
 }
 
-func (fldr *songFolder) search(text string) []*songFolder {
-	results := make([]*songFolder, 0)
-	searchRecursive(fldr, text, 100, &results)
-	return results
-}
-
-func searchRecursive(fldr *songFolder, text string, maxResults int, results *[]*songFolder) {
+// whether any of the sub folders are songs, rather than folders of songs
+func (fldr *songFolder) hasSongs() bool {
 	for _, f := range fldr.subFolders {
-		if len(*results) > maxResults {
-			return
-		}
-		if strings.Contains(strings.ToLower(f.name), strings.ToLower(text)) {
-			*results = append(*results, f)
+		if f.isLeaf {
+			return true
 		}
-		searchRecursive(f, text, maxResults, results)
 	}
+	return false
 }
 
 func incrementSongCount(fldr *songFolder) {
@@ -1,6 +1,9 @@
 package main
 
-import "testing"
+import (
+	"path/filepath"
+	"testing"
+)
 
 func customSearchTest(t *testing.T, root *songFolder, searchText string, expectedSingle *songFolder) {
 	actual := root.search(searchText)
@@ -42,3 +45,109 @@ func TestSongFolderSearch(t *testing.T) {
 	// search should not return root element
 	customSearchTest(t, root, "roo", nil)
 }
+
+func addTestSong(parent *songFolder, name string, info songInfo) *songFolder {
+	song := parent.addSubFolder(name)
+	song.isLeaf = true
+	song.info = &info
+	return song
+}
+
+func searchResultNames(results []searchResult) []string {
+	names := make([]string, len(results))
+	for i, result := range results {
+		names[i] = result.folder.name
+	}
+	return names
+}
+
+func TestSongFolderSearch_Fuzzy(t *testing.T) {
+	root := &songFolder{name: "root", subFolders: []*songFolder{}}
+	gh3 := root.addSubFolder("Guitar Hero III")
+	rb := root.addSubFolder("Rock Band")
+	one := addTestSong(gh3, "One", songInfo{title: "One", artist: "Metallica", album: "...And Justice for All", charter: "Harmonix"})
+	slowRide := addTestSong(gh3, "Slow Ride", songInfo{title: "Slow Ride", artist: "Foghat", album: "Fool for the City", charter: "Neversoft"})
+	enterSandman := addTestSong(rb, "Enter Sandman", songInfo{title: "Enter Sandman", artist: "Metallica", album: "Metallica", charter: "Harmonix"})
+
+	// the artist and title can be mixed
+	results := root.rankedSearch("metallica one")
+	if len(results) != 1 || results[0].folder != one {
+		t.Error("Expected only One, got", searchResultNames(results))
+	}
+
+	// titles count more than albums
+	results = root.rankedSearch("metallica")
+	if len(results) != 2 || results[0].folder != enterSandman {
+		t.Error("Expected Enter Sandman then One, got", searchResultNames(results))
+	}
+
+	// letters in order
+	customSearchTest(t, root, "slwrd", slowRide)
+
+	// typos
+	customSearchTest(t, root, "sandmna", enterSandman)
+	customSearchTest(t, root, "fohgat", slowRide)
+
+	// the game folder
+	results = root.rankedSearch("rock band sandman")
+	if len(results) != 1 || results[0].folder != enterSandman {
+		t.Error("Expected Enter Sandman, got", searchResultNames(results))
+	}
+
+	// the charter
+	results = root.rankedSearch("neversoft")
+	if len(results) != 1 || results[0].folder != slowRide {
+		t.Error("Expected Slow Ride, got", searchResultNames(results))
+	}
+}
+
+func TestSongFolderSearch_HighlightsTheTitle(t *testing.T) {
+	root := &songFolder{name: "root", subFolders: []*songFolder{}}
+	song := addTestSong(root, "Slow Ride", songInfo{title: "Slow Ride", artist: "Foghat"})
+
+	results := root.rankedSearch("ride sl foghat")
+	if len(results) != 1 {
+		t.Fatal("Expected one result, got", searchResultNames(results))
+	}
+	expected := []int{0, 1, 5, 6, 7, 8}
+	actual := results[0].titleMatches
+	if len(actual) != len(expected) {
+		t.Fatalf("Expected %v to be highlighted in %q, got %v", expected, song.name, actual)
+	}
+	for i := range expected {
+		if actual[i] != expected[i] {
+			t.Fatalf("Expected %v to be highlighted in %q, got %v", expected, song.name, actual)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	testCases := []struct {
+		a, b     string
+		expected int
+	}{
+		{"sandman", "sandman", 0},
+		{"sandman", "sandmna", 1},
+		{"sandman", "sadman", 1},
+		{"sandman", "sandmen", 1},
+		{"foghat", "fohgta", 2},
+	}
+	for _, tc := range testCases {
+		if actual := editDistance([]rune(tc.a), []rune(tc.b)); actual != tc.expected {
+			t.Errorf("Expected %q to be %d from %q, got %d", tc.a, tc.expected, tc.b, actual)
+		}
+	}
+}
+
+func TestLoadSongFolder_ReadsSongInfoWhenNeeded(t *testing.T) {
+	rootPath := t.TempDir()
+	writeTestSongFolder(t, filepath.Join(rootPath, "GH3", "One"), "[Song]\n{\n  Artist = \"Metallica\"\n}\n")
+
+	song := loadSongFolder(rootPath).queryFolder([]string{"GH3", "One"})
+	if song.info != nil {
+		t.Fatal("Expected the song info to not be read with the library")
+	}
+	if info := song.loadInfo(); info == nil || info.artist != "Metallica" {
+		t.Error("Expected the info to be read from the chart, got", info)
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// what's known about a song without loading its chart, for searching the library
+type songInfo struct {
+	title      string
+	artist     string
+	album      string
+	charter    string
+	year       string
+	lengthMs   int  // 0 if unknown
+	difficulty int  // the difficulty tier, from 0 to 6, or -1 if unknown
+	hasDrums   bool // whether there's a drums track
+	// song.ini doesn't say whether there are drums, so the chart is checked when the drums filter needs it
+	drumsUnknown bool
+}
+
+// the song.ini difficulties of each part. -1 means that there's no part
+var songIniDifficultyKeys = []string{"diff_guitar", "diff_bass", "diff_rhythm", "diff_drums", "diff_keys", "diff_guitarghl", "diff_bassghl"}
+
+// reads song.ini, and the [Song] section of notes.chart for anything song.ini doesn't have
+func readSongInfo(folderPath string) songInfo {
+	ini := readSongIni(folderPath)
+	info := songInfo{
+		title:   ini["name"],
+		artist:  ini["artist"],
+		album:   ini["album"],
+		charter: ini["charter"],
+		year:    ini["year"],
+	}
+	if info.charter == "" {
+		// older songs call the charter frets
+		info.charter = ini["frets"]
+	}
+
+	info.lengthMs, _ = songIniMs(ini, "song_length")
+	info.difficulty = songIniDifficulty(ini)
+	drumsDifficulty, hasDrumsValue := songIniInt(ini, "diff_drums")
+	info.hasDrums = hasDrumsValue && drumsDifficulty >= 0
+
+	if info.title == "" || info.artist == "" || info.album == "" || info.charter == "" || info.year == "" || info.difficulty < 0 {
+		metadata := readChartSongMetadata(filepath.Join(folderPath, "notes.chart"))
+		info.title = firstNonEmpty(info.title, metadata.Name)
+		info.artist = firstNonEmpty(info.artist, metadata.Artist)
+		info.album = firstNonEmpty(info.album, metadata.Album)
+		info.charter = firstNonEmpty(info.charter, metadata.Charter)
+		info.year = firstNonEmpty(info.year, metadata.Year)
+		if info.difficulty < 0 && metadata.Difficulty > 0 {
+			// charts have Difficulty = 0 when it isn't set
+			info.difficulty = min(metadata.Difficulty, 6)
+		}
+	}
+	info.drumsUnknown = !hasDrumsValue
+	return info
+}
+
+// diff_band is the difficulty of the whole song. without it, the hardest part is used
+func songIniDifficulty(ini map[string]string) int {
+	if difficulty, ok := songIniInt(ini, "diff_band"); ok && difficulty >= 0 {
+		return min(difficulty, 6)
+	}
+	difficulty := -1
+	for _, key := range songIniDifficultyKeys {
+		if partDifficulty, ok := songIniInt(ini, key); ok {
+			difficulty = max(difficulty, partDifficulty)
+		}
+	}
+	return min(difficulty, 6)
+}
+
+// whether the song has a drums track. the chart is only checked the first time, if song.ini doesn't say
+func (fldr *songFolder) hasDrums() bool {
+	info := fldr.loadInfo()
+	if info == nil {
+		return false
+	}
+	if info.drumsUnknown {
+		info.hasDrums = chartHasDrums(filepath.Join(fldr.path, "notes.chart"))
+		info.drumsUnknown = false
+	}
+	return info.hasDrums
+}
+
+// looks for a drums section in the chart, like [ExpertDrums]. charts list the drums before
+// the keys and the six-fret tracks, so the rest of the chart isn't read once one of those starts
+func chartHasDrums(chartPath string) bool {
+	file, err := os.Open(chartPath)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "[") || !strings.HasSuffix(line, "]") {
+			continue
+		}
+		if strings.HasSuffix(line, "Drums]") {
+			return true
+		}
+		if strings.HasSuffix(line, "Keyboard]") || strings.Contains(line, "GHL") {
+			return false
+		}
+	}
+	return false
+}
+
+// reads only the [Song] section at the top of the chart, which is much faster than parsing it
+func readChartSongMetadata(chartPath string) SongMetadata {
+	metadata := SongMetadata{}
+	file, err := os.Open(chartPath)
+	if err != nil {
+		return metadata
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	inSongSection := false
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		if line == "[Song]" {
+			inSongSection = true
+			continue
+		}
+		if !inSongSection || line == "{" {
+			continue
+		}
+		if line == "}" {
+			break
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if found {
+			// fields that can't be parsed are left empty
+			_ = metadata.setField(strings.TrimSpace(key), strings.TrimSpace(value))
+		}
+	}
+	return metadata
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
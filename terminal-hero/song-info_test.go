@@ -0,0 +1,71 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadSongInfo_FallsBackToTheChart(t *testing.T) {
+	folderPath := t.TempDir()
+	err := os.WriteFile(filepath.Join(folderPath, "song.ini"), []byte("[song]\nname = Ini Name\nfrets = Ini Charter\n"), 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chart := "\ufeff[Song]\n{\n  Name = \"Chart Name\"\n  Artist = \"Chart Artist\"\n  Album = \"Chart Album\"\n  Resolution = 192\n}\n[SyncTrack]\n{\n  0 = B 120000\n}\n"
+	err = os.WriteFile(filepath.Join(folderPath, "notes.chart"), []byte(chart), 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info := readSongInfo(folderPath)
+	expected := songInfo{title: "Ini Name", artist: "Chart Artist", album: "Chart Album", charter: "Ini Charter", difficulty: -1, drumsUnknown: true}
+	if info != expected {
+		t.Errorf("Expected %v, got %v", expected, info)
+	}
+}
+
+func TestReadSongInfo_SortingFields(t *testing.T) {
+	folderPath := t.TempDir()
+	ini := "[song]\nname = Test\nyear = 1999\nsong_length = 215000\ndiff_guitar = 3\ndiff_bass = 5\ndiff_drums = -1\n"
+	err := os.WriteFile(filepath.Join(folderPath, "song.ini"), []byte(ini), 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chart := "[Song]\n{\n  Name = \"Test\"\n}\n[ExpertDrums]\n{\n  0 = N 0 0\n}\n"
+	err = os.WriteFile(filepath.Join(folderPath, "notes.chart"), []byte(chart), 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info := readSongInfo(folderPath)
+	if info.year != "1999" || info.lengthMs != 215000 {
+		t.Errorf("Expected 1999 and 215000ms, got %q and %dms", info.year, info.lengthMs)
+	}
+	if info.difficulty != 5 {
+		t.Error("Expected the difficulty of the hardest part, 5, got", info.difficulty)
+	}
+	// song.ini says there are no drums, even though the chart has them
+	if info.hasDrums {
+		t.Error("Expected no drums")
+	}
+}
+
+func TestHasDrums_ChecksTheChartWhenSongIniDoesNotSay(t *testing.T) {
+	rootPath := t.TempDir()
+	writeTestSongFolder(t, filepath.Join(rootPath, "Drums"), "[Song]\n{\n}\n[ExpertSingle]\n{\n}\n[ExpertDrums]\n{\n  0 = N 0 0\n}\n")
+	writeTestSongFolder(t, filepath.Join(rootPath, "Keys"), "[Song]\n{\n}\n[ExpertKeyboard]\n{\n}\n[ExpertDrums]\n{\n}\n")
+
+	root := loadSongFolder(rootPath)
+	drums := root.queryFolder([]string{"Drums"})
+	if !drums.loadInfo().drumsUnknown {
+		t.Fatal("Expected the chart to not be checked for drums until it's needed")
+	}
+	if !drums.hasDrums() || drums.info.drumsUnknown {
+		t.Error("Expected the chart to have drums")
+	}
+	// drums tracks come before the keys, so the chart isn't read past them
+	if root.queryFolder([]string{"Keys"}).hasDrums() {
+		t.Error("Expected drums after the keys to not be found")
+	}
+}
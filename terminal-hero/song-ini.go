@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// reads the key = value lines of a song folder's song.ini. the keys are lowercase.
+// song.ini is optional, so a missing file has no values
+func readSongIni(folderPath string) map[string]string {
+	values := make(map[string]string)
+	file, err := os.Open(filepath.Join(folderPath, "song.ini"))
+	if err != nil {
+		return values
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "[") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+		values[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+	}
+	return values
+}
+
+// a whole number of milliseconds from song.ini. negative values mean the value isn't set
+func songIniMs(values map[string]string, key string) (int, bool) {
+	ms, ok := songIniInt(values, key)
+	if !ok || ms < 0 {
+		return 0, false
+	}
+	return ms, true
+}
+
+func songIniInt(values map[string]string, key string) (int, bool) {
+	value, err := strconv.Atoi(values[key])
+	if err != nil {
+		return 0, false
+	}
+	return value, true
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type songSortMode int
+
+const (
+	sortByName songSortMode = iota
+	sortByArtist
+	sortByYear
+	sortByLength
+	sortByDifficulty
+	sortByIntensity
+	sortByBestScore
+	sortByStars
+	sortByLastPlayed
+	sortByPlayCount
+	songSortModeCount
+)
+
+var songSortModeNames = [songSortModeCount]string{
+	"name", "artist", "year", "length", "difficulty", "intensity", "best score", "stars", "last played", "play count",
+}
+
+func (mode songSortMode) String() string {
+	return songSortModeNames[mode]
+}
+
+func (mode songSortMode) next() songSortMode {
+	return (mode + 1) % songSortModeCount
+}
+
+const maxSongDifficulty = 6
+
+type songFilter struct {
+	unplayed      bool // only songs that have never been played
+	notFullCombo  bool // only songs without a full combo on any track
+	hasDrums      bool // only songs with a drums track
+	minDifficulty int
+	maxDifficulty int
+}
+
+// how the songs in a folder are listed
+type songListOrder struct {
+	sortMode songSortMode
+	filter   songFilter
+}
+
+func defaultSongListOrder() songListOrder {
+	return songListOrder{sortByName, songFilter{maxDifficulty: maxSongDifficulty}}
+}
+
+func (f songFilter) active() bool {
+	return f.unplayed || f.notFullCombo || f.hasDrums || f.difficultyRangeActive()
+}
+
+func (f songFilter) difficultyRangeActive() bool {
+	return f.minDifficulty > 0 || f.maxDifficulty < maxSongDifficulty
+}
+
+// moves the minimum difficulty, keeping it at or below the maximum
+func (f songFilter) changeMinDifficulty(delta int) songFilter {
+	f.minDifficulty = max(0, min(f.minDifficulty+delta, f.maxDifficulty))
+	return f
+}
+
+// moves the maximum difficulty, keeping it at or above the minimum
+func (f songFilter) changeMaxDifficulty(delta int) songFilter {
+	f.maxDifficulty = min(maxSongDifficulty, max(f.maxDifficulty+delta, f.minDifficulty))
+	return f
+}
+
+func (f songFilter) matches(fldr *songFolder) bool {
+	if f.unplayed && fldr.played() {
+		return false
+	}
+	if f.notFullCombo && fldr.fullCombo() {
+		return false
+	}
+	return f.matchesInfo(fldr)
+}
+
+// the filters that only need the song's info, and not its scores. the info is only read when
+// one of these filters is on
+func (f songFilter) matchesInfo(fldr *songFolder) bool {
+	if !f.hasDrums && !f.difficultyRangeActive() {
+		return true
+	}
+	if f.hasDrums && !fldr.hasDrums() {
+		return false
+	}
+	info := fldr.sortInfo()
+	if f.difficultyRangeActive() {
+		// songs without a difficulty only show up when the whole range is allowed
+		if info.difficulty < f.minDifficulty || info.difficulty > f.maxDifficulty {
+			return false
+		}
+	}
+	return true
+}
+
+func (f songFilter) String() string {
+	parts := []string{}
+	if f.unplayed {
+		parts = append(parts, "unplayed")
+	}
+	if f.notFullCombo {
+		parts = append(parts, "not full combo")
+	}
+	if f.hasDrums {
+		parts = append(parts, "drums")
+	}
+	if f.difficultyRangeActive() {
+		parts = append(parts, fmt.Sprintf("difficulty %d-%d", f.minDifficulty, f.maxDifficulty))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// songs from before play counts were recorded only have scores
+func (fldr *songFolder) played() bool {
+	return fldr.plays.PlayCount > 0 || len(fldr.songScore.TrackScores) > 0
+}
+
+func (fldr *songFolder) fullCombo() bool {
+	for _, ts := range fldr.songScore.TrackScores {
+		if ts.TotalNotes > 0 && ts.NotesHit >= ts.TotalNotes {
+			return true
+		}
+	}
+	return false
+}
+
+func (fldr *songFolder) bestScore() int {
+	best := 0
+	for _, ts := range fldr.songScore.TrackScores {
+		best = max(best, ts.Score)
+	}
+	return best
+}
+
+// the intensity of the song's hardest track, or -1 if the chart hasn't been analyzed
+func (fldr *songFolder) intensity() float64 {
+	intensity := -1.0
+	for _, trackIntensity := range fldr.intensities {
+		intensity = max(intensity, trackIntensity)
+	}
+	return intensity
+}
+
+func (fldr *songFolder) bestStars() int {
+	best := 0
+	for _, ts := range fldr.songScore.TrackScores {
+		best = max(best, calcStarCount(ts.Score, ts.TotalNotes))
+	}
+	return best
+}
+
+// folders stay first and in the order they were in, and always show up since their songs
+// haven't been scored yet. the songs after them are filtered and sorted, with the name
+// breaking ties. songs without the value being sorted by go last. playlists keep their own
+// order, so they're only filtered
+func sortAndFilterSongs(folders []*songFolder, order songListOrder, inPlaylistOrder bool) []*songFolder {
+	subFolders := []*songFolder{}
+	songs := []*songFolder{}
+	for _, f := range folders {
+		if !f.isLeaf {
+			subFolders = append(subFolders, f)
+		} else if order.filter.matches(f) {
+			songs = append(songs, f)
+		}
+	}
+
+	if inPlaylistOrder {
+		return append(subFolders, songs...)
+	}
+
+	sort.SliceStable(songs, func(i, j int) bool {
+		if c := compareSongs(songs[i], songs[j], order.sortMode); c != 0 {
+			return c < 0
+		}
+		return compareStrings(songs[i].name, songs[j].name) < 0
+	})
+
+	return append(subFolders, songs...)
+}
+
+// negative when a comes first
+func compareSongs(a *songFolder, b *songFolder, mode songSortMode) int {
+	var aInfo, bInfo songInfo
+	if mode == sortByArtist || mode == sortByYear || mode == sortByLength || mode == sortByDifficulty {
+		// the info is only read when it's sorted by
+		aInfo, bInfo = a.sortInfo(), b.sortInfo()
+	}
+	switch mode {
+	case sortByArtist:
+		return compareKnown(aInfo.artist != "", bInfo.artist != "", compareStrings(aInfo.artist, bInfo.artist))
+	case sortByYear:
+		return compareKnown(aInfo.year != "", bInfo.year != "", compareStrings(aInfo.year, bInfo.year))
+	case sortByLength:
+		return compareKnown(aInfo.lengthMs > 0, bInfo.lengthMs > 0, aInfo.lengthMs-bInfo.lengthMs)
+	case sortByDifficulty:
+		return compareKnown(aInfo.difficulty >= 0, bInfo.difficulty >= 0, aInfo.difficulty-bInfo.difficulty)
+	case sortByIntensity:
+		return compareKnown(a.intensity() >= 0, b.intensity() >= 0, cmp.Compare(a.intensity(), b.intensity()))
+	case sortByBestScore:
+		return b.bestScore() - a.bestScore()
+	case sortByStars:
+		return b.bestStars() - a.bestStars()
+	case sortByLastPlayed:
+		return cmp.Compare(b.plays.LastPlayed, a.plays.LastPlayed)
+	case sortByPlayCount:
+		return b.plays.PlayCount - a.plays.PlayCount
+	}
+	return 0
+}
+
+func (fldr *songFolder) sortInfo() songInfo {
+	if info := fldr.loadInfo(); info != nil {
+		return *info
+	}
+	return songInfo{difficulty: -1}
+}
+
+// unknown values go after known ones
+func compareKnown(aKnown bool, bKnown bool, comparison int) int {
+	if aKnown != bKnown {
+		if aKnown {
+			return -1
+		}
+		return 1
+	}
+	if !aKnown {
+		return 0
+	}
+	return comparison
+}
+
+func compareStrings(a string, b string) int {
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+// what the list title says about the sorting and filtering
+func (order songListOrder) description(inPlaylistOrder bool) string {
+	description := "by " + order.sortMode.String()
+	if inPlaylistOrder {
+		description = "in playlist order"
+	}
+	if order.filter.active() {
+		description += " | " + order.filter.String()
+	}
+	return description
+}
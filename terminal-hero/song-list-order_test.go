@@ -0,0 +1,98 @@
+package main
+
+import (
+	"testing"
+)
+
+func addTestSongWithScore(parent *songFolder, name string, info songInfo, plays songPlays, ts trackScore) *songFolder {
+	song := addTestSong(parent, name, info)
+	song.plays = plays
+	song.songScore.TrackScores = map[string]trackScore{}
+	if ts.TotalNotes > 0 {
+		song.songScore.TrackScores["ExpertSingle"] = ts
+	}
+	return song
+}
+
+func songFolderNames(folders []*songFolder) []string {
+	names := make([]string, len(folders))
+	for i, f := range folders {
+		names[i] = f.name
+	}
+	return names
+}
+
+func customSongOrderTest(t *testing.T, folders []*songFolder, order songListOrder, expected ...string) {
+	actual := songFolderNames(sortAndFilterSongs(folders, order, false))
+	if len(actual) != len(expected) {
+		t.Errorf("Expected %v %s, got %v", expected, order.description(false), actual)
+		return
+	}
+	for i := range expected {
+		if actual[i] != expected[i] {
+			t.Errorf("Expected %v %s, got %v", expected, order.description(false), actual)
+			return
+		}
+	}
+}
+
+func TestSortAndFilterSongs(t *testing.T) {
+	root := &songFolder{name: "root", subFolders: []*songFolder{}}
+	root.addSubFolder("Zebra Pack")
+	addTestSongWithScore(root, "one", songInfo{artist: "Metallica", year: "1988", lengthMs: 446000, difficulty: 5},
+		songPlays{3, 300}, trackScore{Score: 90000, NotesHit: 900, TotalNotes: 1000})
+	addTestSongWithScore(root, "Slow Ride", songInfo{artist: "Foghat", year: "1975", lengthMs: 236000, difficulty: 2, hasDrums: true},
+		songPlays{1, 500}, trackScore{Score: 50000, NotesHit: 800, TotalNotes: 800})
+	addTestSongWithScore(root, "Anthem", songInfo{difficulty: -1, hasDrums: true}, songPlays{}, trackScore{})
+	folders := root.subFolders
+
+	order := defaultSongListOrder()
+	customSongOrderTest(t, folders, order, "Zebra Pack", "Anthem", "one", "Slow Ride")
+
+	// songs without an artist go last
+	order.sortMode = sortByArtist
+	customSongOrderTest(t, folders, order, "Zebra Pack", "Slow Ride", "one", "Anthem")
+
+	order.sortMode = sortByDifficulty
+	customSongOrderTest(t, folders, order, "Zebra Pack", "Slow Ride", "one", "Anthem")
+
+	// by the hardest track. songs that haven't been analyzed go last
+	folders[1].intensities = map[string]float64{"ExpertSingle": 1.5, "EasySingle": 0.5}
+	folders[2].intensities = map[string]float64{"ExpertSingle": 3.2}
+	order.sortMode = sortByIntensity
+	customSongOrderTest(t, folders, order, "Zebra Pack", "one", "Slow Ride", "Anthem")
+
+	order.sortMode = sortByPlayCount
+	customSongOrderTest(t, folders, order, "Zebra Pack", "one", "Slow Ride", "Anthem")
+
+	order.sortMode = sortByLastPlayed
+	customSongOrderTest(t, folders, order, "Zebra Pack", "Slow Ride", "one", "Anthem")
+
+	// folders are never filtered out
+	order.filter.unplayed = true
+	customSongOrderTest(t, folders, order, "Zebra Pack", "Anthem")
+
+	order = defaultSongListOrder()
+	order.filter.notFullCombo = true
+	order.filter.hasDrums = true
+	customSongOrderTest(t, folders, order, "Zebra Pack", "Anthem")
+
+	// songs without a difficulty are filtered out by a difficulty range
+	order = defaultSongListOrder()
+	order.filter = order.filter.changeMinDifficulty(3)
+	customSongOrderTest(t, folders, order, "Zebra Pack", "one")
+}
+
+func TestSongFilter_DifficultyRangeStaysInOrder(t *testing.T) {
+	filter := defaultSongListOrder().filter
+	filter = filter.changeMaxDifficulty(-4)
+	filter = filter.changeMinDifficulty(5)
+	if filter.minDifficulty != 2 || filter.maxDifficulty != 2 {
+		t.Errorf("Expected difficulty 2-2, got %d-%d", filter.minDifficulty, filter.maxDifficulty)
+	}
+
+	filter = filter.changeMinDifficulty(-10).changeMaxDifficulty(10)
+	if filter.active() {
+		t.Error("Expected the whole difficulty range to not filter anything, got", filter)
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/pkg/errors"
+)
+
+const (
+	previewClipDuration    = 25 * time.Second
+	previewFadeInDuration  = time.Second
+	previewFadeOutDuration = 2 * time.Second
+	// where the clip starts when song.ini doesn't say
+	previewDefaultStartFraction = 0.3
+)
+
+var songAudioFilePattern = regexp.MustCompile(`^song\.`)
+
+// the audio files that a preview clip is made from. that's song.ogg if there is one, or all the stems
+func previewClipFilePaths(folderPath string) ([]string, error) {
+	files, err := os.ReadDir(folderPath)
+	if err != nil {
+		return nil, err
+	}
+
+	stems := make([]string, 0)
+	for _, file := range files {
+		name := strings.ToLower(file.Name())
+		if file.IsDir() || !isSupportedAudioFile(name) || strings.HasPrefix(name, "preview.") {
+			continue
+		}
+		filePath := filepath.Join(folderPath, file.Name())
+		if songAudioFilePattern.MatchString(name) {
+			return []string{filePath}, nil
+		}
+		stems = append(stems, filePath)
+	}
+	if len(stems) == 0 {
+		return nil, errors.New("no audio files in " + folderPath)
+	}
+	return stems, nil
+}
+
+// opens a faded clip of the song in the folder, starting at preview_start_time from song.ini
+func openPreviewClip(afo audioFileOpener, folderPath string) (*previewClip, beep.Format, error) {
+	filePaths, err := previewClipFilePaths(folderPath)
+	if err != nil {
+		return nil, beep.Format{}, err
+	}
+
+	clip := &previewClip{}
+	for _, filePath := range filePaths {
+		stream, format, err := afo.openAudioFile(filePath)
+		if err != nil {
+			clip.Close()
+			return nil, beep.Format{}, err
+		}
+		clip.sources = append(clip.sources, playableSound[beep.StreamSeekCloser]{stream, format})
+	}
+	format := clip.sources[0].format
+
+	// the length of the longest stem
+	songLength := time.Duration(0)
+	for _, source := range clip.sources {
+		songLength = max(songLength, source.format.SampleRate.D(source.soundStream.Len()))
+	}
+
+	start := time.Duration(float64(songLength) * previewDefaultStartFraction)
+	if startMs, ok := songIniMs(readSongIni(folderPath), "preview_start_time"); ok {
+		start = time.Duration(startMs) * time.Millisecond
+	}
+	if start >= songLength {
+		start = 0
+	}
+
+	clip.format = format
+	clip.start = start
+	clip.length = format.SampleRate.N(min(previewClipDuration, songLength-start))
+	clip.fadeIn = format.SampleRate.N(previewFadeInDuration)
+	clip.fadeOut = format.SampleRate.N(previewFadeOutDuration)
+
+	err = clip.Seek(0)
+	if err != nil {
+		clip.Close()
+		return nil, beep.Format{}, err
+	}
+	return clip, format, nil
+}
+
+// part of a song made from one or more stems, which fades in and out
+type previewClip struct {
+	sources []playableSound[beep.StreamSeekCloser]
+	format  beep.Format // the format of the first stem, which the others are resampled to
+	start   time.Duration
+	length  int
+	fadeIn  int
+	fadeOut int
+
+	mixed beep.Streamer
+	pos   int
+}
+
+func (c *previewClip) Stream(samples [][2]float64) (n int, ok bool) {
+	if c.pos >= c.length {
+		return 0, false
+	}
+	if len(samples) > c.length-c.pos {
+		samples = samples[:c.length-c.pos]
+	}
+
+	n, _ = c.mixed.Stream(samples)
+	for i := range samples[:n] {
+		p := c.pos + i
+		gain := 1.0
+		if p < c.fadeIn {
+			gain = float64(p) / float64(c.fadeIn)
+		}
+		if remaining := c.length - p; remaining < c.fadeOut {
+			gain = min(gain, float64(remaining)/float64(c.fadeOut))
+		}
+		samples[i][0] *= gain
+		samples[i][1] *= gain
+	}
+	c.pos += n
+	return n, n > 0
+}
+
+func (c *previewClip) Err() error {
+	for _, source := range c.sources {
+		if err := source.soundStream.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *previewClip) Len() int {
+	return c.length
+}
+
+func (c *previewClip) Position() int {
+	return c.pos
+}
+
+// seeks every stem and starts mixing them again
+func (c *previewClip) Seek(p int) error {
+	if p < 0 || p > c.length {
+		return errors.Errorf("preview: seek position %d out of range [0, %d]", p, c.length)
+	}
+
+	at := c.start + c.format.SampleRate.D(p)
+	streams := make([]beep.Streamer, 0, len(c.sources))
+	for _, source := range c.sources {
+		sourcePos := min(source.format.SampleRate.N(at), source.soundStream.Len())
+		err := source.soundStream.Seek(sourcePos)
+		if err != nil {
+			return err
+		}
+
+		var stream beep.Streamer = source.soundStream
+		if source.format.SampleRate != c.format.SampleRate {
+			stream = beep.Resample(4, source.format.SampleRate, c.format.SampleRate, stream)
+		}
+		streams = append(streams, stream)
+	}
+
+	c.mixed = beep.Mix(streams...)
+	c.pos = p
+	return nil
+}
+
+func (c *previewClip) Close() error {
+	var result error
+	for _, source := range c.sources {
+		if err := source.soundStream.Close(); err != nil && result == nil {
+			result = err
+		}
+	}
+	return result
+}
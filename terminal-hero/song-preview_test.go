@@ -0,0 +1,148 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/wav"
+)
+
+// writes a wav file where each sample is a little louder than the last
+func writeTestWav(t *testing.T, filePath string, sampleRate beep.SampleRate, numSamples int) {
+	file, err := os.Create(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	pos := 0
+	ramp := beep.StreamerFunc(func(samples [][2]float64) (n int, ok bool) {
+		for n < len(samples) && pos < numSamples {
+			value := float64(pos) / float64(numSamples)
+			samples[n] = [2]float64{value, -value}
+			n++
+			pos++
+		}
+		return n, n > 0
+	})
+	err = wav.Encode(file, ramp, beep.Format{SampleRate: sampleRate, NumChannels: 2, Precision: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// the samples of the file from the position
+func readTestAudio(t *testing.T, filePath string, pos int, numSamples int) [][2]float64 {
+	stream, _ := openTestAudioFile(t, filePath)
+	err := stream.Seek(pos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	samples := make([][2]float64, numSamples)
+	stream.Stream(samples)
+	return samples
+}
+
+func TestPreviewClip_StartsAtPreviewStartTime(t *testing.T) {
+	folderPath := t.TempDir()
+	songPath := filepath.Join(folderPath, "song.wav")
+	writeTestWav(t, songPath, 1000, 10000)
+	writeTestWav(t, filepath.Join(folderPath, "guitar.wav"), 1000, 10000)
+	err := os.WriteFile(filepath.Join(folderPath, "song.ini"), []byte("[song]\nname = Test\npreview_start_time = 500\n"), 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clip, format, err := openPreviewClip(defaultAudioFileOpener(0), folderPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer clip.Close()
+	if len(clip.sources) != 1 {
+		t.Error("Expected only song.wav to be played, got", len(clip.sources), "files")
+	}
+	if format.SampleRate != 1000 {
+		t.Error("Expected the format of song.wav, got", format)
+	}
+
+	// from 0.5 seconds to the end, with fading
+	if clip.Len() != 9500 {
+		t.Fatal("Expected the clip to go to the end of the song, got", clip.Len())
+	}
+	samples := make([][2]float64, 2000)
+	clip.Stream(samples)
+	if samples[0] != [2]float64{0, 0} {
+		t.Error("Expected the clip to fade in, got", samples[0])
+	}
+	expected := readTestAudio(t, songPath, 1500, 1)[0]
+	if samples[1000] != expected {
+		t.Errorf("Expected sample 1500 of the song after the fade in, %v, got %v", expected, samples[1000])
+	}
+}
+
+func TestPreviewClip_MixesStemsFromPartWayIn(t *testing.T) {
+	folderPath := t.TempDir()
+	guitarPath := filepath.Join(folderPath, "guitar.wav")
+	writeTestWav(t, guitarPath, 1000, 100000)
+	writeTestWav(t, filepath.Join(folderPath, "drums.wav"), 1000, 100000)
+
+	clip, _, err := openPreviewClip(defaultAudioFileOpener(0), folderPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer clip.Close()
+
+	// 30% of 100 seconds, for 25 seconds
+	if clip.Len() != 25000 {
+		t.Fatal("Expected a 25 second clip, got", clip.Len())
+	}
+	samples := make([][2]float64, 1001)
+	clip.Stream(samples)
+	expected := readTestAudio(t, guitarPath, 31000, 1)[0]
+	if samples[1000] != [2]float64{expected[0] * 2, expected[1] * 2} {
+		t.Errorf("Expected both stems from 30 seconds in, %v twice, got %v", expected, samples[1000])
+	}
+}
+
+func TestLoadPreviewSongCmd_FallsBackToTheSong(t *testing.T) {
+	folderPath := t.TempDir()
+	writeTestWav(t, filepath.Join(folderPath, "song.wav"), 1000, 10000)
+
+	previewFilePath := filepath.Join(folderPath, "preview.ogg")
+	msg := loadPreviewSongCmd(defaultAudioFileOpener(0), previewFilePath)()
+	loaded, ok := msg.(previewSongLoadedMsg)
+	if !ok {
+		t.Fatal("Expected the preview to load, got", msg)
+	}
+	defer loaded.previewSound.close()
+	if loaded.previewFilePath != previewFilePath {
+		t.Error("Expected the preview to be for the folder's preview path, got", loaded.previewFilePath)
+	}
+	if _, ok := loaded.previewSound.soundStream.(*previewClip); !ok {
+		t.Error("Expected a clip of the song")
+	}
+}
+
+func TestReadSongIni(t *testing.T) {
+	folderPath := t.TempDir()
+	err := os.WriteFile(filepath.Join(folderPath, "song.ini"), []byte("[Song]\r\nName = Test Song\r\n; a comment\r\nPreview_Start_Time = 42000\r\n"), 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values := readSongIni(folderPath)
+	if values["name"] != "Test Song" {
+		t.Errorf("Expected the name to be %q, got %q", "Test Song", values["name"])
+	}
+	if ms, ok := songIniMs(values, "preview_start_time"); !ok || ms != 42000 {
+		t.Error("Expected the preview to start at 42000ms, got", ms)
+	}
+	if _, ok := songIniMs(values, "song_length"); ok {
+		t.Error("Expected a missing value to not be found")
+	}
+	if len(readSongIni(t.TempDir())) != 0 {
+		t.Error("Expected no values without a song.ini")
+	}
+}
@@ -0,0 +1,342 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const maxSearchResults = 100
+
+// how much a match in each field counts, in percent
+const (
+	searchWeightTitle   = 100
+	searchWeightArtist  = 90
+	searchWeightAlbum   = 60
+	searchWeightCharter = 50
+	searchWeightGame    = 50
+)
+
+type searchResult struct {
+	folder       *songFolder
+	score        int
+	titleMatches []int // the runes of the title that matched, for highlighting
+	typo         bool  // some of the words only matched with typos
+}
+
+type searchField struct {
+	text   []rune // lowercase
+	weight int
+}
+
+type wordMatch struct {
+	score     int
+	positions []int
+	typo      bool
+}
+
+func (m wordMatch) betterThan(other wordMatch) bool {
+	if m.typo != other.typo {
+		return !m.typo
+	}
+	return m.score > other.score
+}
+
+// returns the folders and songs under fldr that match the text, best match first
+func (fldr *songFolder) search(text string) []*songFolder {
+	results := fldr.rankedSearch(text)
+	folders := make([]*songFolder, len(results))
+	for i, result := range results {
+		folders[i] = result.folder
+	}
+	return folders
+}
+
+// every word of the text has to match the name, title, artist, album, charter or game folder of a
+// song. words match as substrings, as letters in order with gaps, or with typos. typo matches are
+// only shown when nothing matches without them
+func (fldr *songFolder) rankedSearch(text string) []searchResult {
+	words := strings.Fields(lowerRunesString(text))
+	results := make([]searchResult, 0)
+	searchRecursive(fldr, words, &results)
+
+	exactResults := make([]searchResult, 0, len(results))
+	for _, result := range results {
+		if !result.typo {
+			exactResults = append(exactResults, result)
+		}
+	}
+	if len(exactResults) > 0 {
+		results = exactResults
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].score != results[j].score {
+			return results[i].score > results[j].score
+		}
+		return results[i].folder.fullTitle() < results[j].folder.fullTitle()
+	})
+	if len(results) > maxSearchResults {
+		results = results[:maxSearchResults]
+	}
+	return results
+}
+
+func searchRecursive(fldr *songFolder, words []string, results *[]searchResult) {
+	for _, f := range fldr.subFolders {
+		if f.playlistName != "" {
+			// the songs in playlists are already in the library
+			continue
+		}
+		if result, ok := matchSongFolder(f, words); ok {
+			*results = append(*results, result)
+		}
+		searchRecursive(f, words, results)
+	}
+}
+
+// the top level folder that the song is in, which is usually the game it's from
+func (fldr *songFolder) gameFolderName() string {
+	for fldr.parent != nil && fldr.parent.parent != nil {
+		fldr = fldr.parent
+	}
+	return fldr.name
+}
+
+func (fldr *songFolder) searchFields() []searchField {
+	fields := []searchField{{[]rune(lowerRunesString(fldr.name)), searchWeightTitle}}
+	if info := fldr.loadInfo(); info != nil {
+		fields = append(fields,
+			searchField{[]rune(lowerRunesString(info.title)), searchWeightTitle},
+			searchField{[]rune(lowerRunesString(info.artist)), searchWeightArtist},
+			searchField{[]rune(lowerRunesString(info.album)), searchWeightAlbum},
+			searchField{[]rune(lowerRunesString(info.charter)), searchWeightCharter},
+		)
+	}
+	if fldr.parent != nil {
+		fields = append(fields, searchField{[]rune(lowerRunesString(fldr.gameFolderName())), searchWeightGame})
+	}
+	return fields
+}
+
+func matchSongFolder(fldr *songFolder, words []string) (searchResult, bool) {
+	result := searchResult{folder: fldr}
+	fields := fldr.searchFields()
+	for _, word := range words {
+		wordRunes := []rune(word)
+		best := wordMatch{}
+		found := false
+		for _, field := range fields {
+			match, ok := matchWord(field.text, wordRunes)
+			if !ok {
+				continue
+			}
+			match.score = match.score * field.weight / 100
+			if !found || match.betterThan(best) {
+				best = match
+				found = true
+			}
+		}
+		if !found {
+			return searchResult{}, false
+		}
+		result.score += best.score
+		result.typo = result.typo || best.typo
+	}
+
+	result.titleMatches = titleMatchPositions(fldr.fullTitle(), words)
+	return result, true
+}
+
+// the runes of the title that the words match, for highlighting
+func titleMatchPositions(title string, words []string) []int {
+	titleRunes := []rune(lowerRunesString(title))
+	matched := make(map[int]bool)
+	for _, word := range words {
+		match, ok := matchWord(titleRunes, []rune(word))
+		if !ok {
+			continue
+		}
+		for _, p := range match.positions {
+			matched[p] = true
+		}
+	}
+
+	positions := make([]int, 0, len(matched))
+	for p := range matched {
+		positions = append(positions, p)
+	}
+	sort.Ints(positions)
+	return positions
+}
+
+// the best way that the word matches the field. substrings score highest, then letters in order, then typos
+func matchWord(field []rune, word []rune) (wordMatch, bool) {
+	if len(word) == 0 {
+		return wordMatch{}, true
+	}
+	if match, ok := matchSubstring(field, word); ok {
+		return match, true
+	}
+	if match, ok := matchSubsequence(field, word); ok {
+		return match, true
+	}
+	return matchWithTypos(field, word)
+}
+
+func isWordStart(field []rune, i int) bool {
+	return i == 0 || !isWordRune(field[i-1])
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func matchSubstring(field []rune, word []rune) (wordMatch, bool) {
+	best := wordMatch{}
+	found := false
+	for i := 0; i+len(word) <= len(field); i++ {
+		if string(field[i:i+len(word)]) != string(word) {
+			continue
+		}
+		score := 100 + 10*len(word)
+		if isWordStart(field, i) {
+			score += 30
+		}
+		if len(word) == len(field) {
+			score += 20
+		}
+		if !found || score > best.score {
+			best = wordMatch{score: score, positions: runeRange(i, i+len(word))}
+			found = true
+		}
+	}
+	return best, found
+}
+
+// the letters of the word in order, with gaps between them. each place the first letter
+// appears is tried, taking the rest of the letters as early as possible
+func matchSubsequence(field []rune, word []rune) (wordMatch, bool) {
+	best := wordMatch{}
+	found := false
+	for start := range field {
+		if field[start] != word[0] {
+			continue
+		}
+
+		positions := []int{start}
+		for i := start + 1; i < len(field) && len(positions) < len(word); i++ {
+			if field[i] == word[len(positions)] {
+				positions = append(positions, i)
+			}
+		}
+		if len(positions) < len(word) {
+			break
+		}
+
+		gaps := positions[len(positions)-1] - positions[0] + 1 - len(positions)
+		score := 50 + 5*len(word) - 2*gaps
+		for _, p := range positions {
+			if isWordStart(field, p) {
+				score += 5
+			}
+		}
+		score = max(score, 1)
+		if !found || score > best.score {
+			best = wordMatch{score: score, positions: positions}
+			found = true
+		}
+	}
+	return best, found
+}
+
+// how many typos a word can have. short words have to be typed exactly
+func allowedTypos(wordLength int) int {
+	if wordLength < 4 {
+		return 0
+	} else if wordLength < 8 {
+		return 1
+	}
+	return 2
+}
+
+// compares the word to each word in the field, and to the start of each word for words that are still being typed
+func matchWithTypos(field []rune, word []rune) (wordMatch, bool) {
+	allowed := allowedTypos(len(word))
+	if allowed == 0 {
+		return wordMatch{}, false
+	}
+
+	best := wordMatch{}
+	found := false
+	for start := 0; start < len(field); start++ {
+		if !isWordRune(field[start]) || !isWordStart(field, start) {
+			continue
+		}
+		end := start
+		for end < len(field) && isWordRune(field[end]) {
+			end++
+		}
+
+		candidates := []int{end}
+		if prefixEnd := start + len(word); prefixEnd < end {
+			candidates = append(candidates, prefixEnd)
+		}
+		for _, candidateEnd := range candidates {
+			distance := editDistance(field[start:candidateEnd], word)
+			if distance > allowed {
+				continue
+			}
+			score := max(30+3*len(word)-15*distance, 1)
+			if !found || score > best.score {
+				best = wordMatch{score: score, positions: runeRange(start, candidateEnd), typo: true}
+				found = true
+			}
+		}
+		start = end
+	}
+	return best, found
+}
+
+// the number of letters that have to be added, removed, changed or swapped with the next one to turn a into b
+func editDistance(a []rune, b []rune) int {
+	rows := make([][]int, len(a)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(b)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			rows[i][j] = min(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				rows[i][j] = min(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+	return rows[len(a)][len(b)]
+}
+
+func runeRange(start int, end int) []int {
+	positions := make([]int, 0, end-start)
+	for i := start; i < end; i++ {
+		positions = append(positions, i)
+	}
+	return positions
+}
+
+// lowercases each rune on its own, so rune positions are the same as in s
+func lowerRunesString(s string) string {
+	runes := []rune(s)
+	for i, r := range runes {
+		runes[i] = unicode.ToLower(r)
+	}
+	return string(runes)
+}
@@ -7,7 +7,6 @@ import (
 
 	"github.com/charmbracelet/log"
 	"github.com/faiface/beep"
-	"github.com/faiface/beep/speaker"
 )
 
 const (
@@ -18,11 +17,18 @@ const (
 
 type speakerState int
 
+// how much audio the speaker takes from the streamers at a time
+const speakerBufferDuration = time.Second / 10
+
 type thSpeaker struct {
 	state speakerState
 
 	format beep.Format
 	mu     sync.Mutex
+
+	outputKind    string // one of the audioOutput constants
+	outputWavPath string // where the wav output is written
+	output        audioOutput
 }
 
 type playableSound[T beep.Streamer] struct {
@@ -57,8 +63,14 @@ func (spkr *thSpeaker) finishInit() {
 		panic(fmt.Sprintf("Speaker not in correct state to finish init: %d", spkr.state))
 	}
 
-	bufSize := spkr.format.SampleRate.N(time.Second / 10)
-	speaker.Init(spkr.format.SampleRate, bufSize)
+	bufSize := spkr.format.SampleRate.N(speakerBufferDuration)
+	output, err := openAudioOutput(spkr.outputKind, spkr.outputWavPath, spkr.format.SampleRate, bufSize)
+	if err != nil {
+		// the game still works without sound
+		log.Error("failed to open audio output, playing without sound", "output", spkr.outputKind, "err", err)
+		output, _ = openAudioOutput(audioOutputNull, "", spkr.format.SampleRate, bufSize)
+	}
+	spkr.output = output
 	log.Info(fmt.Sprintf("Initialized speaker with format %d", spkr.format))
 	spkr.state = speakerFullyInitialized
 }
@@ -88,7 +100,7 @@ func (spkr *thSpeaker) play(stream beep.Streamer, format beep.Format) {
 	}
 
 	log.Info("playing sound")
-	speaker.Play(stream)
+	spkr.output.play(stream)
 }
 
 func (spkr *thSpeaker) resampleIfNeeded(stream beep.Streamer, oldFormat beep.Format) playableSound[beep.Streamer] {
@@ -128,5 +140,24 @@ func resampleIntoBuffer(spkr soundPlayer, stream beep.Streamer, oldFormat beep.F
 }
 
 func (spkr *thSpeaker) clear() {
-	speaker.Clear()
+	spkr.mu.Lock()
+	defer spkr.mu.Unlock()
+
+	if spkr.output != nil {
+		spkr.output.clear()
+	}
+}
+
+// stops the output, which finishes writing the wav output
+func (spkr *thSpeaker) close() error {
+	spkr.mu.Lock()
+	defer spkr.mu.Unlock()
+
+	if spkr.output == nil {
+		return nil
+	}
+	err := spkr.output.close()
+	spkr.output = nil
+	spkr.state = speakerNotInitialized
+	return err
 }
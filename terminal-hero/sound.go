@@ -11,21 +11,31 @@ import (
 
 	"github.com/faiface/beep"
 	"github.com/faiface/beep/effects"
+	"github.com/faiface/beep/mp3"
 	"github.com/faiface/beep/wav"
 )
 
 const (
-	instrumentGuitar = "Guitar"
-	instrumentBass   = "Bass"
-	instrumentDrums  = "Drums"
-	instrumentMisc   = "Misc"
+	instrumentGuitar     = "Guitar"
+	instrumentBass       = "Bass"
+	instrumentRhythm     = "Rhythm"
+	instrumentCoopGuitar = "CoopGuitar"
+	instrumentDrums      = "Drums"
+	instrumentKeys       = "Keys"
+	instrumentMisc       = "Misc"
+
+	// six-fret tracks use the same stems as the five-fret ones
+	instrumentGHLGuitar = "GHLGuitar"
+	instrumentGHLBass   = "GHLBass"
 )
 
 var instrumentSoundFiles = map[string]*regexp.Regexp{
 	instrumentDrums:  takeRegex(regexp.Compile(`^drums(_[0-9]+)?\.`)),
 	instrumentGuitar: takeRegex(regexp.Compile(`^guitar\.`)),
-	instrumentBass:   takeRegex(regexp.Compile(`^(bass|rhythm)\.`)),
-	instrumentMisc:   takeRegex(regexp.Compile(`^(song|vocals|keys)\.`)),
+	instrumentBass:   takeRegex(regexp.Compile(`^bass\.`)),
+	instrumentRhythm: takeRegex(regexp.Compile(`^rhythm\.`)),
+	instrumentKeys:   takeRegex(regexp.Compile(`^keys\.`)),
+	instrumentMisc:   takeRegex(regexp.Compile(`^(song|vocals)\.`)),
 }
 
 func isMatchingInstrumentSoundFile(instrument string, fileName string) bool {
@@ -59,6 +69,19 @@ type songSounds struct {
 	song   playableSound[beep.StreamSeeker]
 	bass   playableSound[*effects.Volume]
 	drums  playableSound[*effects.Volume]
+	keys   playableSound[*effects.Volume]
+	rhythm playableSound[*effects.Volume]
+}
+
+// closes the files that the stems are streamed from. the stems must not be playing
+func (ss songSounds) close() {
+	closeStreamSeeker(ss.song.soundStream)
+	for _, stem := range []*effects.Volume{ss.guitar.soundStream, ss.bass.soundStream, ss.drums.soundStream,
+		ss.keys.soundStream, ss.rhythm.soundStream} {
+		if stem != nil {
+			closeStreamSeeker(stem.Streamer)
+		}
+	}
 }
 
 func loadSoundEffects(spkr soundPlayer) (soundEffects, error) {
@@ -80,6 +103,12 @@ func getAudioDecoderForFile(filePath string) decoderFunc {
 		return DecodeVorbis
 	} else if strings.HasSuffix(filePath, ".wav") {
 		return wavDecoder
+	} else if strings.HasSuffix(filePath, ".mp3") {
+		return mp3.Decode
+	} else if strings.HasSuffix(filePath, ".flac") {
+		return DecodeFlac
+	} else if strings.HasSuffix(filePath, ".opus") {
+		return DecodeOpus
 	} else {
 		return nil
 	}
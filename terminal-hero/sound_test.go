@@ -0,0 +1,267 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/faiface/beep"
+	"github.com/mewkiz/flac"
+	"github.com/mewkiz/flac/frame"
+	"github.com/mewkiz/flac/meta"
+)
+
+func TestIsSupportedAudioFile(t *testing.T) {
+	for _, fileName := range []string{"song.ogg", "song.wav", "song.mp3", "song.flac", "song.opus"} {
+		if !isSupportedAudioFile(fileName) {
+			t.Error("Expected", fileName, "to be supported")
+		}
+	}
+	for _, fileName := range []string{"song.m4a", "notes.chart", "song"} {
+		if isSupportedAudioFile(fileName) {
+			t.Error("Expected", fileName, "to not be supported")
+		}
+	}
+}
+
+// writes a flac file with one frame per item in frames. each frame has the samples for each channel
+func writeTestFlac(t *testing.T, channels frame.Channels, bitsPerSample uint8, frames [][][]int32) string {
+	filePath := filepath.Join(t.TempDir(), "test.flac")
+	file, err := os.Create(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info := &meta.StreamInfo{
+		BlockSizeMin:  16,
+		BlockSizeMax:  65535,
+		SampleRate:    44100,
+		NChannels:     uint8(channels.Count()),
+		BitsPerSample: bitsPerSample,
+	}
+	enc, err := flac.NewEncoder(file, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, samples := range frames {
+		f := &frame.Frame{
+			Header: frame.Header{
+				HasFixedBlockSize: false,
+				BlockSize:         uint16(len(samples[0])),
+				SampleRate:        44100,
+				Channels:          channels,
+				BitsPerSample:     bitsPerSample,
+			},
+		}
+		for _, channelSamples := range samples {
+			f.Subframes = append(f.Subframes, &frame.Subframe{
+				SubHeader: frame.SubHeader{Pred: frame.PredVerbatim},
+				Samples:   channelSamples,
+				NSamples:  len(channelSamples),
+			})
+		}
+		err = enc.WriteFrame(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err = enc.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return filePath
+}
+
+func rampSamples(start int32, count int, step int32) []int32 {
+	samples := make([]int32, count)
+	for i := range samples {
+		samples[i] = start + int32(i)*step
+	}
+	return samples
+}
+
+func openTestAudioFile(t *testing.T, filePath string) (beep.StreamSeekCloser, beep.Format) {
+	stream, format, err := openAudioFileNonBuffered(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { stream.Close() })
+	return stream, format
+}
+
+func TestDecodeFlac_MultichannelPlaysFrontLeftAndRight(t *testing.T) {
+	// 24 bit left, right and center
+	filePath := writeTestFlac(t, frame.ChannelsLRC, 24, [][][]int32{
+		{rampSamples(0, 32, 1000), rampSamples(0, 32, -1000), rampSamples(4000000, 32, 0)},
+		{rampSamples(32000, 32, 1000), rampSamples(-32000, 32, -1000), rampSamples(4000000, 32, 0)},
+	})
+
+	stream, format := openTestAudioFile(t, filePath)
+	if format.NumChannels != 3 || format.SampleRate != 44100 {
+		t.Fatal("Expected 3 channels at 44100Hz, got", format)
+	}
+	if stream.Len() != 64 {
+		t.Fatal("Expected 64 samples, got", stream.Len())
+	}
+
+	samples := make([][2]float64, 100)
+	n, _ := stream.Stream(samples)
+	if n != 64 {
+		t.Fatal("Expected to stream 64 samples, got", n)
+	}
+	scale := 1 / float64(1<<23)
+	if samples[40][0] != 40000*scale || samples[40][1] != -40000*scale {
+		t.Error("Expected the left and right channels, got", samples[40])
+	}
+
+	// seeking to the middle of a frame
+	err := stream.Seek(40)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, _ = stream.Stream(samples)
+	if n != 24 || samples[0][0] != 40000*scale {
+		t.Errorf("Expected 24 samples starting at sample 40, got %d starting at %v", n, samples[0])
+	}
+	if stream.Position() != 64 {
+		t.Error("Expected the position to be 64, got", stream.Position())
+	}
+}
+
+func TestDecodeFlac_MonoPlaysOnBothSides(t *testing.T) {
+	filePath := writeTestFlac(t, frame.ChannelsMono, 16, [][][]int32{{rampSamples(-100, 16, 10)}})
+
+	stream, _ := openTestAudioFile(t, filePath)
+	samples := make([][2]float64, 16)
+	stream.Stream(samples)
+	for _, sample := range samples {
+		if sample[0] != sample[1] {
+			t.Fatal("Expected mono to be the same on both sides, got", sample)
+		}
+	}
+	if samples[0][0] != -100/float64(1<<15) {
+		t.Error("Expected the first sample to be -100, got", samples[0][0]*(1<<15))
+	}
+}
+
+func TestDecodeOpus(t *testing.T) {
+	filePath, err := filepath.Abs(filepath.Join("testdata", "tiny.opus"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stream, format := openTestAudioFile(t, filePath)
+	if format.SampleRate != 48000 || format.NumChannels != 1 {
+		t.Fatal("Expected mono at 48000Hz, got", format)
+	}
+
+	// the last granule position minus the pre-skip
+	if stream.Len() != 279 {
+		t.Fatal("Expected 279 samples, got", stream.Len())
+	}
+
+	samples := make([][2]float64, 1000)
+	n, _ := stream.Stream(samples)
+	if n != 279 {
+		t.Fatal("Expected to stream 279 samples, got", n)
+	}
+	for _, sample := range samples[:n] {
+		if sample[0] != sample[1] {
+			t.Fatal("Expected mono to be the same on both sides, got", sample)
+		}
+	}
+	if n, ok := stream.Stream(samples); n != 0 || ok {
+		t.Error("Expected the stream to be finished")
+	}
+
+	err = stream.Seek(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, _ = stream.Stream(samples)
+	if n != 179 {
+		t.Error("Expected to stream 179 samples after seeking to 100, got", n)
+	}
+}
+
+// writes an ogg page holding one packet
+func writeTestOggPage(buf *bytes.Buffer, headerType byte, granule uint64, pageIndex uint32, packet []byte) {
+	page := []byte("OggS")
+	page = append(page, 0, headerType)
+	page = binary.LittleEndian.AppendUint64(page, granule)
+	page = binary.LittleEndian.AppendUint32(page, 1) // the stream serial number
+	page = binary.LittleEndian.AppendUint32(page, pageIndex)
+	page = append(page, 0, 0, 0, 0) // the checksum
+	page = append(page, byte(len(packet)/255+1))
+	for i := 0; i < len(packet)/255; i++ {
+		page = append(page, 255)
+	}
+	page = append(page, byte(len(packet)%255))
+	page = append(page, packet...)
+
+	// crc32 with the 0x04c11db7 polynomial, without reflection
+	var crc uint32
+	for _, b := range page {
+		crc ^= uint32(b) << 24
+		for i := 0; i < 8; i++ {
+			if crc&0x80000000 != 0 {
+				crc = crc<<1 ^ 0x04c11db7
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	binary.LittleEndian.PutUint32(page[22:], crc)
+	buf.Write(page)
+}
+
+func TestDecodeOpus_MultistreamPlaysFrontLeftAndRight(t *testing.T) {
+	// the only audio packet in tiny.opus, which is a mono packet with a single frame (code 0)
+	tinyPacket := []byte{0x48, 0x83, 0xca, 0xde, 0x8a, 0xe5, 0x67, 0xd5, 0x1c, 0xac, 0xa2, 0x54, 0xfa, 0xff, 0xbf}
+	tinyPath, err := filepath.Abs(filepath.Join("testdata", "tiny.opus"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mono, _ := openTestAudioFile(t, tinyPath)
+	expected := make([][2]float64, 279)
+	mono.Stream(expected)
+
+	// 4 mono streams, front left from the last stream and front right from the first,
+	// which is self-delimited by adding the frame length after the TOC byte
+	head := []byte("OpusHead")
+	head = append(head, 1, 4)
+	head = binary.LittleEndian.AppendUint16(head, 312)
+	head = binary.LittleEndian.AppendUint32(head, 48000)
+	head = append(head, 0, 0, 1, 2, 0, 1, 0, 255, 255)
+	audio := append([]byte{tinyPacket[0], byte(len(tinyPacket) - 1)}, tinyPacket[1:]...)
+	audio = append(audio, tinyPacket...)
+
+	buf := &bytes.Buffer{}
+	writeTestOggPage(buf, 2, 0, 0, head)
+	writeTestOggPage(buf, 0, 0, 1, append([]byte("OpusTags"), 0, 0, 0, 0, 0, 0, 0, 0))
+	writeTestOggPage(buf, 4, 591, 2, audio)
+	filePath := filepath.Join(t.TempDir(), "surround.opus")
+	err = os.WriteFile(filePath, buf.Bytes(), 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stream, format := openTestAudioFile(t, filePath)
+	if format.NumChannels != 4 || stream.Len() != 279 {
+		t.Fatalf("Expected 4 channels and 279 samples, got %v and %d", format, stream.Len())
+	}
+	samples := make([][2]float64, 1000)
+	n, _ := stream.Stream(samples)
+	if n != 279 {
+		t.Fatal("Expected to stream 279 samples, got", n)
+	}
+	for i := range expected {
+		if samples[i][0] != expected[i][0] || samples[i][1] != expected[i][0] {
+			t.Fatalf("Expected sample %d to be %v on both sides, got %v", i, expected[i][0], samples[i])
+		}
+	}
+}
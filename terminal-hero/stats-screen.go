@@ -37,6 +37,7 @@ type statsScreenModel struct {
 	db                 grDbAccessor
 	soundEffect        statsScreenSoundLoadedMsg
 	speaker            *thSpeaker
+	nextSongName       string // the next song in the setlist, if there is one
 }
 
 type statsScreenSoundLoadedMsg struct {
@@ -45,10 +46,7 @@ type statsScreenSoundLoadedMsg struct {
 }
 
 func initialStatsScreenModel(ci chartInfo, ps playStats, songRootPath string, db grDbAccessor, spkr *thSpeaker) statsScreenModel {
-	var sssErr error = nil
-	if !ps.failed {
-		sssErr = saveSongScore(db, ci, ps, songRootPath)
-	}
+	sssErr := saveSongPlay(db, ci, ps, songRootPath)
 
 	return statsScreenModel{
 		chartInfo:          ci,
@@ -60,7 +58,8 @@ func initialStatsScreenModel(ci chartInfo, ps playStats, songRootPath string, db
 	}
 }
 
-func saveSongScore(db grDbAccessor, ci chartInfo, ps playStats, songRootPath string) error {
+// counts the play, and saves the score if the song was passed
+func saveSongPlay(db grDbAccessor, ci chartInfo, ps playStats, songRootPath string) error {
 	chartPath := filepath.Join(ci.fullFolderPath, "notes.chart")
 	fileHash, err := hashFileByPath(chartPath)
 	if err != nil {
@@ -73,6 +72,10 @@ func saveSongScore(db grDbAccessor, ci chartInfo, ps playStats, songRootPath str
 
 	s := song{fileHash, relative, ci.songName()}
 
+	err = db.recordSongPlay(s)
+	if err != nil || ps.failed {
+		return err
+	}
 	return db.setSongScore(s, ci.track.fullTrackName, ps.score, ps.notesHitGrouped, ps.totalNotes)
 }
 
@@ -180,8 +183,12 @@ func (m statsScreenModel) View() string {
 		sb.WriteString(errorStyle.Render("\n\nError playing sound effect: "+m.soundEffect.err.Error()) + "\n")
 	}
 
+	continueText := "Press ENTER To continue"
+	if m.nextSongName != "" {
+		continueText = "Press ENTER To play " + m.nextSongName
+	}
 	sb.WriteString(lipgloss.NewStyle().Background(lipgloss.Color("#b6b3fc")).Foreground(lipgloss.Color("#000000")).
-		Padding(1, 3, 1, 3).Margin(3, 1, 1, 2).Bold(true).Render("Press ENTER To continue"))
+		Padding(1, 3, 1, 3).Margin(3, 1, 1, 2).Bold(true).Render(continueText))
 
 	return sb.String()
 }
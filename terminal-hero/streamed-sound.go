@@ -0,0 +1,199 @@
+package main
+
+import (
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/faiface/beep"
+)
+
+const (
+	// decoded audio is passed from the read ahead goroutine to the speaker in chunks this long
+	streamChunkDuration = time.Second / 4
+	// how many chunks are decoded before they're needed
+	streamReadAheadChunks = 8
+)
+
+// a sound that is decoded from its files while it plays instead of being loaded into memory.
+// a goroutine decodes a few chunks ahead of the speaker so that decoding doesn't cause gaps
+type streamedSound struct {
+	sources      []beep.StreamSeekCloser // mixed together, all in sourceFormat
+	sourceFormat beep.Format
+	format       beep.Format // the format that is streamed, which is resampled from sourceFormat if needed
+
+	chunks  chan [][2]float64
+	stop    chan struct{}
+	done    chan struct{}
+	current [][2]float64 // the part of the last chunk that hasn't been streamed yet
+
+	pos    int // in samples of format
+	length int
+	err    error
+	closed bool
+}
+
+func newStreamedSound(sources []beep.StreamSeekCloser, sourceFormat beep.Format, format beep.Format) (*streamedSound, error) {
+	sourceLen := 0
+	for _, source := range sources {
+		if source.Len() > sourceLen {
+			sourceLen = source.Len()
+		}
+	}
+
+	s := &streamedSound{
+		sources:      sources,
+		sourceFormat: sourceFormat,
+		format:       format,
+		length:       format.SampleRate.N(sourceFormat.SampleRate.D(sourceLen)),
+	}
+	err := s.start()
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// seeks the sources to pos and starts decoding from there
+func (s *streamedSound) start() error {
+	sourcePos := s.sourceFormat.SampleRate.N(s.format.SampleRate.D(s.pos))
+	for _, source := range s.sources {
+		p := sourcePos
+		if p > source.Len() {
+			p = source.Len()
+		}
+		err := source.Seek(p)
+		if err != nil {
+			return err
+		}
+	}
+
+	var stream beep.Streamer
+	if len(s.sources) == 1 {
+		stream = s.sources[0]
+	} else {
+		streams := make([]beep.Streamer, len(s.sources))
+		for i, source := range s.sources {
+			streams[i] = source
+		}
+		stream = beep.Mix(streams...)
+	}
+	if s.sourceFormat.SampleRate != s.format.SampleRate {
+		stream = beep.Resample(8, s.sourceFormat.SampleRate, s.format.SampleRate, stream)
+	}
+
+	s.chunks = make(chan [][2]float64, streamReadAheadChunks)
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	go readAhead(stream, s.format.SampleRate.N(streamChunkDuration), s.chunks, s.stop, s.done)
+	return nil
+}
+
+// stops the read ahead goroutine and throws away what it decoded
+func (s *streamedSound) halt() {
+	if s.stop == nil {
+		return
+	}
+	close(s.stop)
+	<-s.done
+	s.stop = nil
+	s.current = nil
+}
+
+func readAhead(stream beep.Streamer, chunkLen int, chunks chan<- [][2]float64, stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	defer close(chunks)
+
+	for {
+		chunk := make([][2]float64, chunkLen)
+		n, ok := stream.Stream(chunk)
+		if n > 0 {
+			select {
+			case chunks <- chunk[:n]:
+			case <-stop:
+				return
+			}
+		}
+		if !ok {
+			return
+		}
+	}
+}
+
+func (s *streamedSound) Stream(samples [][2]float64) (n int, ok bool) {
+	if s.closed {
+		return 0, false
+	}
+
+	for n < len(samples) {
+		if len(s.current) == 0 {
+			chunk, more := <-s.chunks
+			if !more {
+				// the resampled length can be a little different than the calculated length
+				s.pos = s.length
+				return n, n > 0
+			}
+			s.current = chunk
+		}
+
+		streamed := 0
+		for streamed < len(s.current) && n < len(samples) {
+			samples[n] = s.current[streamed]
+			streamed++
+			n++
+		}
+		s.current = s.current[streamed:]
+		s.pos += streamed
+	}
+
+	if s.pos > s.length {
+		s.pos = s.length
+	}
+	return n, true
+}
+
+func (s *streamedSound) Err() error {
+	return s.err
+}
+
+func (s *streamedSound) Len() int {
+	return s.length
+}
+
+func (s *streamedSound) Position() int {
+	return s.pos
+}
+
+func (s *streamedSound) Seek(p int) error {
+	if s.closed {
+		return nil
+	}
+	if p < 0 {
+		p = 0
+	}
+	if p > s.length {
+		p = s.length
+	}
+
+	s.halt()
+	s.pos = p
+	s.err = s.start()
+	return s.err
+}
+
+// closes the files. the sound must not be playing
+func (s *streamedSound) Close() error {
+	if s.closed {
+		return nil
+	}
+	s.halt()
+	s.closed = true
+
+	var result error
+	for _, source := range s.sources {
+		if err := source.Close(); err != nil {
+			log.Error("failed to close sound", "err", err)
+			result = err
+		}
+	}
+	return result
+}
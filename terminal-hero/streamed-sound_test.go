@@ -0,0 +1,121 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/faiface/beep"
+)
+
+// a sound where each sample is its own position
+type fakeStemStreamer struct {
+	length   int
+	pos      int
+	seekedTo int
+	closed   bool
+}
+
+func (f *fakeStemStreamer) Stream(samples [][2]float64) (n int, ok bool) {
+	for n < len(samples) && f.pos < f.length {
+		samples[n] = [2]float64{float64(f.pos), float64(f.pos)}
+		f.pos++
+		n++
+	}
+	return n, n > 0
+}
+
+func (f *fakeStemStreamer) Err() error    { return nil }
+func (f *fakeStemStreamer) Len() int      { return f.length }
+func (f *fakeStemStreamer) Position() int { return f.pos }
+func (f *fakeStemStreamer) Close() error  { f.closed = true; return nil }
+
+func (f *fakeStemStreamer) Seek(p int) error {
+	f.pos = p
+	f.seekedTo = p
+	return nil
+}
+
+func streamAll(s beep.Streamer) [][2]float64 {
+	result := make([][2]float64, 0)
+	samples := make([][2]float64, 1000)
+	for {
+		n, ok := s.Stream(samples)
+		for i := 0; i < n; i++ {
+			result = append(result, samples[i])
+		}
+		if !ok {
+			return result
+		}
+	}
+}
+
+func TestStreamedSound_StreamsAndSeeks(t *testing.T) {
+	format := beep.Format{SampleRate: 44100, NumChannels: 2, Precision: 2}
+	source := &fakeStemStreamer{length: 44100 * 3}
+	s, err := newStreamedSound([]beep.StreamSeekCloser{source}, format, format)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s.Len() != source.length {
+		t.Fatal("Expected the length to be", source.length, "got", s.Len())
+	}
+
+	samples := streamAll(s)
+	if len(samples) != source.length {
+		t.Fatal("Expected", source.length, "samples, got", len(samples))
+	}
+	for i, sample := range samples {
+		if sample[0] != float64(i) {
+			t.Fatalf("Expected sample %d to be %d, got %v", i, i, sample[0])
+		}
+	}
+	if s.Position() != s.Len() {
+		t.Error("Expected the position to be at the end, got", s.Position())
+	}
+
+	err = s.Seek(44100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	samples = streamAll(s)
+	if len(samples) != 44100*2 || samples[0][0] != 44100 {
+		t.Errorf("Expected to stream from 44100 after seeking, got %d samples starting at %v", len(samples), samples[0])
+	}
+
+	s.Close()
+	s.Close()
+	if !source.closed {
+		t.Error("Expected the source to be closed")
+	}
+	if n, ok := s.Stream(make([][2]float64, 10)); n != 0 || ok {
+		t.Error("Expected a closed sound to not stream")
+	}
+}
+
+func TestStreamedSound_MixesAndResamples(t *testing.T) {
+	sourceFormat := beep.Format{SampleRate: 22050, NumChannels: 2, Precision: 2}
+	format := beep.Format{SampleRate: 44100, NumChannels: 2, Precision: 2}
+	short := &fakeStemStreamer{length: 22050}
+	long := &fakeStemStreamer{length: 22050 * 2}
+
+	s, err := newStreamedSound([]beep.StreamSeekCloser{short, long}, sourceFormat, format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	if s.Len() != 44100*2 {
+		t.Fatal("Expected the length of the longest source in the new sample rate, got", s.Len())
+	}
+
+	// seeking is in the new sample rate
+	s.Seek(44100)
+	if short.seekedTo != 22050 || long.seekedTo != 22050 {
+		t.Error("Expected the sources to be seeked to 22050, got", short.seekedTo, long.seekedTo)
+	}
+
+	streamAll(s)
+	if s.Position() != s.Len() {
+		t.Error("Expected the position to be at the end, got", s.Position())
+	}
+}
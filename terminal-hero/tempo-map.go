@@ -0,0 +1,88 @@
+package main
+
+import "sort"
+
+const defaultBpm = 120000 // the BPM (times 1000) used before the first B event
+
+type tempoChange struct {
+	tick   int
+	timeMs float64
+	bpm    float64 // times 1000, like B events
+}
+
+// TempoMap converts between ticks and milliseconds using the B events of a chart
+type TempoMap struct {
+	resolution float64
+	changes    []tempoChange // sorted by tick, the first one is always at tick 0
+}
+
+func NewTempoMap(chart *Chart) *TempoMap {
+	resolution := float64(chart.SongMetadata.Resolution)
+	if resolution <= 0 {
+		resolution = 192
+	}
+
+	changes := []tempoChange{{0, 0, defaultBpm}}
+	for _, sync := range chart.SyncTrack {
+		if sync.Type != "B" || sync.Value <= 0 || sync.TimeStamp < 0 {
+			continue
+		}
+
+		last := changes[len(changes)-1]
+		if sync.TimeStamp <= last.tick {
+			// a later event at the same tick replaces the earlier one
+			changes[len(changes)-1].bpm = float64(sync.Value)
+			continue
+		}
+
+		timeMs := last.timeMs + timeElapsed(float64(sync.TimeStamp-last.tick), last.bpm, resolution)
+		changes = append(changes, tempoChange{sync.TimeStamp, timeMs, float64(sync.Value)})
+	}
+
+	return &TempoMap{resolution, changes}
+}
+
+// the tempo change that is in effect at the tick
+func (t *TempoMap) changeAtTick(tick float64) tempoChange {
+	i := sort.Search(len(t.changes), func(i int) bool {
+		return float64(t.changes[i].tick) > tick
+	})
+	if i == 0 {
+		return t.changes[0]
+	}
+	return t.changes[i-1]
+}
+
+// TickToMs returns the time in milliseconds since the start of the chart
+func (t *TempoMap) TickToMs(tick int) float64 {
+	return t.FractionalTickToMs(float64(tick))
+}
+
+func (t *TempoMap) FractionalTickToMs(tick float64) float64 {
+	change := t.changeAtTick(tick)
+	return change.timeMs + timeElapsed(tick-float64(change.tick), change.bpm, t.resolution)
+}
+
+// MsToTick returns the tick at the time, including the fraction of a tick
+func (t *TempoMap) MsToTick(ms float64) float64 {
+	i := sort.Search(len(t.changes), func(i int) bool {
+		return t.changes[i].timeMs > ms
+	})
+	change := t.changes[0]
+	if i > 0 {
+		change = t.changes[i-1]
+	}
+
+	msPerTick := timeElapsed(1, change.bpm, t.resolution)
+	return float64(change.tick) + (ms-change.timeMs)/msPerTick
+}
+
+// the BPM (times 1000) at the tick
+func (t *TempoMap) BpmAtTick(tick int) float64 {
+	return t.changeAtTick(float64(tick)).bpm
+}
+
+// the length in milliseconds of a sustain or phrase starting at the tick, taking tempo changes into account
+func (t *TempoMap) DurationMs(tick int, lengthTicks int64) float64 {
+	return t.FractionalTickToMs(float64(tick)+float64(lengthTicks)) - t.TickToMs(tick)
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+// walks every B event from the start, like the original note timing code did
+func linearTickToMs(chart *Chart, tick int) float64 {
+	resolution := float64(chart.SongMetadata.Resolution)
+	currentTime := float64(0)
+	currentTick := 0
+	currentBpm := float64(120000)
+	for _, sync := range chart.SyncTrack {
+		if sync.TimeStamp > tick {
+			break
+		}
+		if sync.Type != "B" {
+			continue
+		}
+		currentTime += timeElapsed(float64(sync.TimeStamp-currentTick), currentBpm, resolution)
+		currentTick = sync.TimeStamp
+		currentBpm = float64(sync.Value)
+	}
+	return currentTime + timeElapsed(float64(tick-currentTick), currentBpm, resolution)
+}
+
+func TestTempoMap_MatchesSampleChartBpmChanges(t *testing.T) {
+	chartPaths, err := filepath.Glob("sample-songs/*.chart")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, chartPath := range chartPaths {
+		chart := openSampleChart(chartPath, t)
+		tempoMap := NewTempoMap(chart)
+
+		bpmChanges := 0
+		for _, sync := range chart.SyncTrack {
+			if sync.Type != "B" {
+				continue
+			}
+			bpmChanges++
+
+			// at the change, and halfway through the previous beat
+			for _, tick := range []int{sync.TimeStamp, sync.TimeStamp - chart.SongMetadata.Resolution/2} {
+				if tick < 0 {
+					continue
+				}
+				expected := linearTickToMs(chart, tick)
+				ms := tempoMap.TickToMs(tick)
+				if math.Abs(expected-ms) > 0.000001 {
+					t.Fatalf("%s: expected tick %d to be at %fms, got %fms", chartPath, tick, expected, ms)
+				}
+
+				backToTick := tempoMap.MsToTick(ms)
+				if math.Abs(backToTick-float64(tick)) > 0.000001 {
+					t.Fatalf("%s: expected %fms to be tick %d, got %f", chartPath, ms, tick, backToTick)
+				}
+			}
+
+			if tempoMap.BpmAtTick(sync.TimeStamp) != float64(sync.Value) {
+				t.Fatalf("%s: expected BPM %d at tick %d, got %f", chartPath, sync.Value, sync.TimeStamp, tempoMap.BpmAtTick(sync.TimeStamp))
+			}
+		}
+
+		if bpmChanges < 2 {
+			t.Errorf("%s: expected the sample chart to have BPM changes", chartPath)
+		}
+	}
+}
+
+func TestTempoMap_FractionalBpm(t *testing.T) {
+	chart := openCultOfPersonalityChart(t)
+	tempoMap := NewTempoMap(chart)
+
+	// the chart starts at 98.684 BPM and changes to 97.087 BPM at tick 768
+	expected := 4 * 60000 / 98.684
+	if math.Abs(tempoMap.TickToMs(768)-expected) > 0.000001 {
+		t.Errorf("Expected tick 768 to be at %fms, got %fms", expected, tempoMap.TickToMs(768))
+	}
+
+	expected += 60000 / 97.087 / 2
+	if math.Abs(tempoMap.TickToMs(768+96)-expected) > 0.000001 {
+		t.Errorf("Expected tick 864 to be at %fms, got %fms", expected, tempoMap.TickToMs(864))
+	}
+
+	if math.Abs(tempoMap.MsToTick(expected)-864) > 0.000001 {
+		t.Errorf("Expected %fms to be tick 864, got %f", expected, tempoMap.MsToTick(expected))
+	}
+}
+
+func TestTempoMap_DurationSpansTempoChanges(t *testing.T) {
+	chart := &Chart{
+		SongMetadata: SongMetadata{Resolution: 100},
+		SyncTrack:    []SyncTrackElement{{0, "B", 60000}, {100, "B", 120000}, {100, "TS", 4}},
+	}
+	tempoMap := NewTempoMap(chart)
+
+	// one beat at 60 BPM then one beat at 120 BPM
+	if tempoMap.DurationMs(0, 200) != 1500 {
+		t.Error("Expected 1500ms, got", tempoMap.DurationMs(0, 200))
+	}
+	if tempoMap.MsToTick(1250) != 150 {
+		t.Error("Expected tick 150, got", tempoMap.MsToTick(1250))
+	}
+}
+
+func TestTempoMap_NoTempo(t *testing.T) {
+	chart := &Chart{SongMetadata: SongMetadata{Resolution: 192}}
+	tempoMap := NewTempoMap(chart)
+
+	if tempoMap.TickToMs(192) != 500 {
+		t.Error("Expected 120 BPM to be used, got", tempoMap.TickToMs(192))
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	maxTrackIntensity = 6
+	// notes closer together than this are part of a fast run
+	fastRunGapMs = 150
+	// the window that the peak notes per second is measured over, so one flam isn't a peak
+	peakWindowMs = 2000
+)
+
+// how hard a track is, from the timing of its notes. chords count as one hit
+type trackIntensity struct {
+	peakNps    float64 // the most hits per second in any 2 seconds
+	averageNps float64 // hits per second from the first note to the last
+	chordRatio float64 // the fraction of the hits that are chords
+	fastRun    int     // the most hits in a row with fastRunGapMs or less between them
+	rating     float64 // from 0 to 6, rounded to one decimal
+}
+
+func (ti trackIntensity) String() string {
+	return fmt.Sprintf("%.1f", ti.rating)
+}
+
+// the notes are the output of getNotesWithRealTimestamps, so their timestamps are in milliseconds
+func analyzeTrackIntensity(track trackName, notes []Note) trackIntensity {
+	// the times of each hit, and how many notes are in it
+	hitTimes := []int{}
+	chords := 0
+	notesInHit := 0
+	for _, note := range notes {
+		if !isPlayedNoteType(track, note.RawNoteType) {
+			continue
+		}
+		if len(hitTimes) > 0 && hitTimes[len(hitTimes)-1] == note.TimeStamp {
+			notesInHit++
+			if notesInHit == 2 {
+				chords++
+			}
+			continue
+		}
+		hitTimes = append(hitTimes, note.TimeStamp)
+		notesInHit = 1
+	}
+	if len(hitTimes) == 0 {
+		return trackIntensity{}
+	}
+
+	ti := trackIntensity{fastRun: 1}
+	ti.chordRatio = float64(chords) / float64(len(hitTimes))
+	durationMs := max(hitTimes[len(hitTimes)-1]-hitTimes[0], 1000)
+	ti.averageNps = float64(len(hitTimes)) * 1000 / float64(durationMs)
+
+	windowStart := 0
+	run := 1
+	for i, t := range hitTimes {
+		for t-hitTimes[windowStart] >= peakWindowMs {
+			windowStart++
+		}
+		ti.peakNps = max(ti.peakNps, float64(i-windowStart+1)*1000/peakWindowMs)
+
+		if i > 0 && t-hitTimes[i-1] <= fastRunGapMs {
+			run++
+			ti.fastRun = max(ti.fastRun, run)
+		} else {
+			run = 1
+		}
+	}
+
+	ti.rating = trackIntensityRating(ti)
+	return ti
+}
+
+// weighs each measurement from 0 to 1 between what an easy track and a very hard track have
+func trackIntensityRating(ti trackIntensity) float64 {
+	scale := func(value float64, easy float64, hard float64) float64 {
+		return min(max((value-easy)/(hard-easy), 0), 1)
+	}
+	rating := 0.4*scale(ti.peakNps, 1, 13) +
+		0.3*scale(ti.averageNps, 0.5, 7) +
+		0.15*scale(ti.chordRatio, 0, 0.5) +
+		0.15*scale(float64(ti.fastRun), 1, 64)
+	return math.Round(rating*maxTrackIntensity*10) / 10
+}
+
+// whether the note is hit, rather than marking something about the notes at the same time,
+// like forced or tap notes and cymbals
+func isPlayedNoteType(track trackName, rawNoteType int) bool {
+	if track.instrument == instrumentDrums {
+		return rawNoteType == drumsKickNoteType || (rawNoteType >= 1 && rawNoteType <= 5)
+	}
+	_, _, ok := laneForNoteType(track, rawNoteType)
+	return ok
+}
+
+// the intensity of every track in the chart. the map keys are full track names
+func chartIntensities(chart *Chart) map[string]trackIntensity {
+	result := make(map[string]trackIntensity)
+	for name := range chart.Tracks {
+		track := parseChartTrackName(chart, name)
+		result[name] = analyzeTrackIntensity(track, getNotesWithRealTimestamps(chart, name))
+	}
+	return result
+}
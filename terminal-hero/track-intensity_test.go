@@ -0,0 +1,95 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeTrackIntensity(t *testing.T) {
+	guitar := trackName{"Expert", 3, instrumentGuitar, "ExpertSingle"}
+	notes := []Note{
+		// a chord, with a forced note marker that isn't counted
+		{0, 0, 0}, {0, 1, 0}, {0, 5, 0},
+		{1000, 2, 0},
+	}
+	// a run of 16th notes
+	for i := 0; i < 20; i++ {
+		notes = append(notes, Note{2000 + i*100, i % 5, 0})
+	}
+
+	ti := analyzeTrackIntensity(guitar, notes)
+	if ti.fastRun != 20 {
+		t.Error("Expected a fast run of 20 notes, got", ti.fastRun)
+	}
+	if ti.chordRatio != 1.0/22 {
+		t.Error("Expected 1 of the 22 hits to be a chord, got", ti.chordRatio)
+	}
+	if ti.peakNps != 10 {
+		t.Error("Expected a peak of 10 notes per second, got", ti.peakNps)
+	}
+	if ti.rating <= 0 || ti.rating > maxTrackIntensity {
+		t.Error("Expected a rating between 0 and 6, got", ti.rating)
+	}
+
+	if empty := analyzeTrackIntensity(guitar, []Note{}); empty.rating != 0 {
+		t.Error("Expected a track without notes to have no intensity, got", empty.rating)
+	}
+}
+
+func TestChartIntensities_HarderTracksRateHigher(t *testing.T) {
+	intensities := chartIntensities(openSampleChart("sample-songs/ttfaf.chart", t))
+	easy, medium, expert := intensities["EasySingle"], intensities["MediumSingle"], intensities["ExpertSingle"]
+	if !(easy.rating < medium.rating && medium.rating < expert.rating) {
+		t.Errorf("Expected the intensity to go up with the difficulty, got easy %s, medium %s, expert %s", easy, medium, expert)
+	}
+
+	schoolsOut := chartIntensities(openSampleChart("sample-songs/schools-out.chart", t))["ExpertSingle"]
+	if schoolsOut.rating >= expert.rating {
+		t.Errorf("Expected School's Out to be less intense than TTFAF, got %s and %s", schoolsOut, expert)
+	}
+}
+
+func TestLoadSongModel_ShowsTrackIntensities(t *testing.T) {
+	chart := openCultOfPersonalityChart(t)
+
+	m := initialLoadModel("", defaultSettings(), nil, nil)
+	lm, _ := m.Update(loadedChartMsg{chart: chart, intensities: chartIntensities(chart)})
+	m = lm.(loadSongModel).selectTrackAutomatically("ExpertSingle")
+
+	for _, item := range m.menuList.Items() {
+		if !strings.Contains(item.(difficultyVm).Title(), "intensity") {
+			t.Error("Expected the difficulty to show its intensity, got", item.(difficultyVm).Title())
+		}
+	}
+}
+
+func TestLoadChartIntensities_UsesTheCachedRatings(t *testing.T) {
+	db, err := openAndMigrateTestDb()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.destroy(t)
+
+	folderPath := t.TempDir()
+	chartHash := writeTestSongFolder(t, folderPath, sixFretChart)
+	chart := parseTestChart(t, sixFretChart)
+
+	analyzed := loadChartIntensities(chart, folderPath, db)
+	cached, ok, err := db.getChartIntensities(chartHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok || cached["ExpertGHLGuitar"] != analyzed["ExpertGHLGuitar"].rating {
+		t.Fatal("Expected the analyzed ratings to be cached, got", cached)
+	}
+
+	// the cached rating is used instead of analyzing the chart again
+	err = db.setTrackIntensities(chartHash, map[string]float64{"ExpertGHLGuitar": 5.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	intensities := loadChartIntensities(chart, folderPath, db)
+	if intensities["ExpertGHLGuitar"].rating != 5.5 {
+		t.Error("Expected the cached rating of 5.5, got", intensities["ExpertGHLGuitar"])
+	}
+}
@@ -43,7 +43,7 @@ func getInstrumentNames(tracks []string) []string {
 	return instrumentNames
 }
 
-var preferredInstrumentOrder = []string{"Guitar", "Bass", "Drums", "Keys", "Vocals", "Backing", "Rhythm"}
+var preferredInstrumentOrder = []string{"Guitar", "Bass", "Drums", "Keys", "GHLGuitar", "GHLBass", "Vocals", "Backing", "Rhythm", "CoopGuitar"}
 
 func sortTrackNames(trackNames []trackName) []trackName {
 	organized2 := organizeTrackNames2(trackNames)
@@ -150,6 +150,12 @@ func translateInstrumentName(instrument string) string {
 		return "Guitar"
 	case "DoubleBass":
 		return "Bass"
+	case "DoubleRhythm":
+		return instrumentRhythm
+	case "DoubleGuitar":
+		return instrumentCoopGuitar
+	case "Keyboard":
+		return instrumentKeys
 	default:
 		return instrument
 	}
@@ -159,7 +165,8 @@ func parseTrackName(track string) trackName {
 	var wordMatcher = regexp.MustCompile(`[A-Z][a-z]+`)
 	words := wordMatcher.FindAllString(track, -1)
 	if len(words) >= 2 {
-		instrumentWords := strings.Join(words[1:], "")
+		// the rest of the name instead of the matched words, so that acronyms like GHL are kept
+		instrumentWords := strings.TrimPrefix(track, words[0])
 		dv := getDifficultyValue(words[0])
 		tn := trackName{words[0], dv, translateInstrumentName(instrumentWords), track}
 		// fmt.Printf("%v\n", tn)
@@ -169,6 +176,16 @@ func parseTrackName(track string) trackName {
 	return trackName{"", 1, "", track}
 }
 
+// like parseTrackName, but the DoubleBass track is rhythm guitar when the
+// chart says that the second player plays rhythm
+func parseChartTrackName(chart *Chart, track string) trackName {
+	tn := parseTrackName(track)
+	if strings.HasSuffix(track, "DoubleBass") && strings.EqualFold(chart.SongMetadata.Player2, "rhythm") {
+		tn.instrument = instrumentRhythm
+	}
+	return tn
+}
+
 func getDifficultyValue(difficulty string) int {
 	switch difficulty {
 	case "Easy":
@@ -190,6 +207,16 @@ func instrumentDisplayName(instrument string) string {
 		return "Guitar 🎸"
 	case "Drums":
 		return "Drums 🥁"
+	case instrumentKeys:
+		return "Keys 🎹"
+	case instrumentRhythm:
+		return "Rhythm Guitar 🎸"
+	case instrumentCoopGuitar:
+		return "Co-op Guitar 🎸"
+	case instrumentGHLGuitar:
+		return "Guitar (6 fret) 🎸"
+	case instrumentGHLBass:
+		return "Bass (6 fret)"
 	}
 	return instrument
 }
@@ -222,11 +249,12 @@ func pluralize(count int, singular string, plural string) string {
 
 func setupKeymapForList(list *list.Model) {
 	ctrlf := key.NewBinding(key.WithKeys("ctrl+f"), key.WithHelp("ctrl+f", "search"))
+	ctrle := key.NewBinding(key.WithKeys("ctrl+e"), key.WithHelp("ctrl+e", "edit chart"))
 	list.AdditionalShortHelpKeys = func() []key.Binding {
 		return []key.Binding{ctrlf}
 	}
 	list.AdditionalFullHelpKeys = func() []key.Binding {
-		return []key.Binding{ctrlf}
+		return []key.Binding{ctrlf, ctrle}
 	}
 	list.KeyMap.NextPage.SetKeys("right", "d", "l")
 	list.KeyMap.NextPage.SetHelp("→/l/d", "right")
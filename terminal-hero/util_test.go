@@ -4,6 +4,8 @@ import (
 	"math/rand"
 	"reflect"
 	"testing"
+
+	"github.com/faiface/beep/effects"
 )
 
 func TestGetInstrumentNames(t *testing.T) {
@@ -169,6 +171,22 @@ func TestParseTrackName(t *testing.T) {
 			track:    "EasyDoubleBass",
 			expected: trackName{"Easy", easy, "Bass", "EasyDoubleBass"},
 		},
+		{
+			track:    "ExpertGHLGuitar",
+			expected: trackName{"Expert", expert, "GHLGuitar", "ExpertGHLGuitar"},
+		},
+		{
+			track:    "HardKeyboard",
+			expected: trackName{"Hard", hard, "Keys", "HardKeyboard"},
+		},
+		{
+			track:    "ExpertDoubleRhythm",
+			expected: trackName{"Expert", expert, "Rhythm", "ExpertDoubleRhythm"},
+		},
+		{
+			track:    "MediumDoubleGuitar",
+			expected: trackName{"Medium", medium, "CoopGuitar", "MediumDoubleGuitar"},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -176,6 +194,38 @@ func TestParseTrackName(t *testing.T) {
 	}
 }
 
+func TestParseChartTrackName_Player2Rhythm(t *testing.T) {
+	chart := &Chart{}
+	if parseChartTrackName(chart, "ExpertDoubleBass").instrument != instrumentBass {
+		t.Error("Expected DoubleBass to be bass without Player2")
+	}
+
+	chart.SongMetadata.Player2 = "rhythm"
+	if instrument := parseChartTrackName(chart, "ExpertDoubleBass").instrument; instrument != instrumentRhythm {
+		t.Error("Expected DoubleBass to be rhythm when Player2 = rhythm, got", instrument)
+	}
+	if instrument := parseChartTrackName(chart, "ExpertSingle").instrument; instrument != instrumentGuitar {
+		t.Error("Expected Single to still be guitar, got", instrument)
+	}
+}
+
+func TestGetSongSoundForInstrument_RhythmStem(t *testing.T) {
+	rhythm := playableSound[*effects.Volume]{soundStream: &effects.Volume{}}
+	ss := songSounds{rhythm: rhythm}
+
+	if ss.getSongSoundForInstrument(instrumentRhythm).soundStream != rhythm.soundStream {
+		t.Error("Expected rhythm to use the rhythm stem")
+	}
+	if ss.getSongSoundForInstrument(instrumentBass).soundStream != rhythm.soundStream {
+		t.Error("Expected bass to use the rhythm stem when there's no bass stem")
+	}
+
+	ss.bass = playableSound[*effects.Volume]{soundStream: &effects.Volume{}}
+	if ss.getSongSoundForInstrument(instrumentBass).soundStream != ss.bass.soundStream {
+		t.Error("Expected bass to use the bass stem")
+	}
+}
+
 func customTestRelativePath(t *testing.T, current string, root string, expected string) {
 	actual, err := relativePath(current, root)
 	if err != nil {
@@ -253,12 +303,12 @@ func TestInstrumentSoundFiles(t *testing.T) {
 	customInstrumentSoundFileTest(t, instrumentGuitar, "geetar.ogg", false)
 
 	customInstrumentSoundFileTest(t, instrumentBass, "bass.ogg", true)
-	customInstrumentSoundFileTest(t, instrumentBass, "rhythm.ogg", true)
+	customInstrumentSoundFileTest(t, instrumentRhythm, "rhythm.ogg", true)
 	customInstrumentSoundFileTest(t, instrumentBass, "bassfish.ogg", false)
 
 	customInstrumentSoundFileTest(t, instrumentMisc, "song.ogg", true)
 	customInstrumentSoundFileTest(t, instrumentMisc, "vocals.ogg", true)
-	customInstrumentSoundFileTest(t, instrumentMisc, "keys.ogg", true)
+	customInstrumentSoundFileTest(t, instrumentKeys, "keys.ogg", true)
 	//customInstrumentSoundFileTest(t, instrumentMisc, "crowd.ogg", true)
 }
 